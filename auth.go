@@ -0,0 +1,17 @@
+package request
+
+import (
+	"encoding/base64"
+)
+
+// WithBearer sets the Authorization header of the request to the bearer token. Other headers are kept intact.
+func WithBearer(r *Request, token string) {
+	r.SetHeader("Authorization", "Bearer "+token)
+}
+
+// WithBasicAuth sets the Authorization header of the request to use HTTP Basic authentication with the provided
+// username and password. The encoding is identical to [http.Request.SetBasicAuth].
+func WithBasicAuth(r *Request, username, password string) {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	r.SetHeader("Authorization", "Basic "+auth)
+}
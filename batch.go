@@ -0,0 +1,96 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// BatchResult is the outcome of a single request sent by [SendBatch]. Index is the position of the request in the
+// given slice.
+type BatchResult struct {
+	Index    int
+	Response *Response
+	Err      error
+}
+
+// SendBatch sends the requests concurrently using [Send], with at most concurrency requests in flight at once. If
+// concurrency is not positive, all requests are sent at once. The same client is used for all of them, so its
+// connection pool is shared.
+//
+// The returned slice has one result per request, in the same order. Once ctx is cancelled no new requests are issued,
+// and the results of the remaining ones hold the context's error.
+func SendBatch(ctx context.Context, client *http.Client, reqs []Request, concurrency int, opts ...Option) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, reqs, concurrency,
+		func(i int, r Request) { results[i].Response, results[i].Err = Send(ctx, client, r, opts...) },
+		func(i int, err error) { results[i].Err = err },
+	)
+	return results
+}
+
+// ParseResult is the outcome of a single request sent by [SendParseBatch]. Index is the position of the request in
+// the given slice, and Value is its decoded response.
+type ParseResult[T any] struct {
+	Index int
+	Value *T
+	Err   error
+}
+
+// SendParseBatch is the typed counterpart of [SendBatch], sending the requests concurrently using [SendParse], with at
+// most concurrency requests in flight at once. It is meant for fanning out many calls sharing the same response
+// structure. Acceptable status codes are handled as by [SendParse], and apply to all the requests.
+//
+// The returned slice has one result per request, in the same order. Once ctx is cancelled no new requests are issued,
+// and the results of the remaining ones hold the context's error.
+func SendParseBatch[T any](
+	ctx context.Context, client *http.Client, reqs []Request, concurrency int, acceptable ...int,
+) []ParseResult[T] {
+	results := make([]ParseResult[T], len(reqs))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, reqs, concurrency,
+		func(i int, r Request) { results[i].Value, results[i].Err = SendParse[T](ctx, client, r, acceptable...) },
+		func(i int, err error) { results[i].Err = err },
+	)
+	return results
+}
+
+// runBatch calls send for each request concurrently, with at most concurrency calls running at once, and waits for
+// them to return. Requests not sent because ctx is done are passed to skip along with the context's error instead.
+func runBatch(
+	ctx context.Context, reqs []Request, concurrency int, send func(int, Request), skip func(int, error),
+) {
+	if concurrency <= 0 {
+		concurrency = len(reqs)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, r := range reqs {
+		if ctx.Err() != nil {
+			skip(i, ctx.Err())
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			skip(i, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			send(i, r)
+		}()
+	}
+	wg.Wait()
+}
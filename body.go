@@ -0,0 +1,93 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// NewJSON creates a [Request] whose Body is the JSON encoding of v. It also sets the Content-Type header to
+// application/json. The returned value can be further modified by the caller, e.g. to add more headers.
+func NewJSON(method Method, url string, v any) (Request, error) {
+	return newJSON(method, url, v, "application/json")
+}
+
+// NewMergePatch creates a PATCH [Request] whose Body is the JSON Merge Patch v, as described by RFC 7396, and sets the
+// Content-Type header to application/merge-patch+json. Fields set to null in v remove the corresponding fields of the
+// target.
+func NewMergePatch(url string, v any) (Request, error) {
+	return newJSON(PATCH, url, v, "application/merge-patch+json")
+}
+
+// PatchOperation is a single operation of a JSON Patch, as described by RFC 6902. Op is one of add, remove, replace,
+// move, copy or test; Path and From are JSON Pointers. From is only used by move and copy. Value is omitted for remove,
+// move and copy, which take none, and is always sent for the other operations; a nil Value is encoded as null.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON implements [encoding/json.Marshaler], omitting Value for the operations which take none. The operation is
+// encoded using [Marshal], so that Value goes through the configured codec.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	switch o.Op {
+	case "remove", "move", "copy":
+		return Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+			From string `json:"from,omitempty"`
+		}{o.Op, o.Path, o.From})
+	}
+	// patchOperation has the same fields, without the method, so that marshaling it doesn't recurse.
+	type patchOperation PatchOperation
+	return Marshal(patchOperation(o))
+}
+
+// NewJSONPatch creates a PATCH [Request] whose Body is the JSON Patch consisting of ops, and sets the Content-Type
+// header to application/json-patch+json.
+func NewJSONPatch(url string, ops []PatchOperation) (Request, error) {
+	if ops == nil {
+		ops = []PatchOperation{}
+	}
+	return newJSON(PATCH, url, ops, "application/json-patch+json")
+}
+
+// newJSON creates a [Request] whose Body is the JSON encoding of v, with contentType as its Content-Type header.
+func newJSON(method Method, url string, v any, contentType string) (Request, error) {
+	body, err := Marshal(v)
+	if err != nil {
+		return Request{}, fmt.Errorf("marshaling body: %w", err)
+	}
+
+	r := Request{
+		Method: method,
+		URL:    url,
+		Header: http.Header{"Content-Type": {contentType}},
+		Body:   body,
+	}
+	return r, nil
+}
+
+// NewForm creates a [Request] whose Body is the URL-encoded form of values, and sets the Content-Type header to
+// application/x-www-form-urlencoded. It is independent of [Request.Params], which only affects the query string.
+func NewForm(method Method, url string, values url.Values) Request {
+	return Request{
+		Method: method,
+		URL:    url,
+		Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:   []byte(values.Encode()),
+	}
+}
+
+// NewText creates a [Request] with the string body, and sets the Content-Type header to contentType, e.g. text/plain
+// or application/xml.
+func NewText(method Method, url, body, contentType string) Request {
+	return Request{
+		Method: method,
+		URL:    url,
+		Header: http.Header{"Content-Type": {contentType}},
+		Body:   []byte(body),
+	}
+}
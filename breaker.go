@@ -0,0 +1,157 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by [SendBreaker] when the [Breaker] does not allow any requests.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of a [Breaker].
+type BreakerState int
+
+const (
+	// BreakerClosed lets all requests through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all requests until the cooldown is over.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial request through, whose outcome decides the next state.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a circuit breaker, stopping requests to a failing backend. After Threshold consecutive failures it opens,
+// and for the Cooldown duration rejects all requests. Then it half-opens, letting a single trial request decide
+// whether it closes again or goes back to being open. Transport errors and 5xx status codes count as failures, but 4xx
+// ones don't.
+//
+// It must be created with [NewBreaker], and is safe for concurrent use.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewBreaker returns a closed [Breaker]. Values of threshold less than one are treated as one.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: max(threshold, 1), cooldown: cooldown}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return b.state
+}
+
+// Failures returns the number of consecutive failures recorded so far.
+func (b *Breaker) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// allow reports whether a request may be sent at this moment, and if so, whether it is the half-open trial.
+func (b *Breaker) allow() (trial, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+
+	switch b.state {
+	case BreakerClosed:
+		return false, true
+	case BreakerHalfOpen:
+		if b.trial {
+			return false, false
+		}
+		b.trial = true
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// record updates the breaker with the outcome of a request allowed by it, trial being the value returned by allow. If
+// counted is false, the outcome was neither a success nor a failure, such as the caller cancelling the request; a trial
+// with such an outcome lets another one through. Only the trial's outcome decides what a half-open breaker becomes;
+// outcomes of other requests, which were in flight when the breaker opened, are ignored unless it is closed.
+func (b *Breaker) record(trial, failed, counted bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trial = false
+		if !counted {
+			return
+		}
+		if failed {
+			b.failures++
+			b.state = BreakerOpen
+			b.openedAt = time.Now()
+			return
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	if !counted || b.state != BreakerClosed {
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// refresh half-opens the breaker if its cooldown is over. b.mu must be held.
+func (b *Breaker) refresh() {
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+		b.trial = false
+	}
+}
+
+// SendBreaker is like [Send], but guarded by the [Breaker]. If the breaker doesn't allow the request, it is not sent
+// and [ErrCircuitOpen] is returned.
+func SendBreaker(ctx context.Context, client *http.Client, r Request, b *Breaker, opts ...Option) (*Response, error) {
+	trial, ok := b.allow()
+	if !ok {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := Send(ctx, client, r, opts...)
+	if err != nil {
+		b.record(trial, true, ctx.Err() == nil)
+		return resp, err
+	}
+	b.record(trial, resp.IsServerError(), true)
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Builder provides a chainable way of creating a [Request]. It must be created with [NewBuilder], and finalized by
+// calling [Builder.Build].
+type Builder struct {
+	r    Request
+	err  error
+	gzip bool
+}
+
+// NewBuilder returns a [Builder] for a [Request] with the given method and URL.
+func NewBuilder(method Method, url string) *Builder {
+	return &Builder{r: Request{Method: method, URL: url}}
+}
+
+// Header adds the value to the given header key.
+func (b *Builder) Header(key, value string) *Builder {
+	b.r.AddHeader(key, value)
+	return b
+}
+
+// Cookie adds the cookie to the request.
+func (b *Builder) Cookie(c *http.Cookie) *Builder {
+	b.r.Cookies = append(b.r.Cookies, c)
+	return b
+}
+
+// Param sets the query parameter key to value.
+func (b *Builder) Param(key, value string) *Builder {
+	if b.r.Params == nil {
+		b.r.Params = make(map[string]string)
+	}
+	b.r.Params[key] = value
+	return b
+}
+
+// JSONBody sets the request body to the JSON encoding of v, and sets the Content-Type header to application/json.
+// Marshaling errors are returned by [Builder.Build].
+func (b *Builder) JSONBody(v any) *Builder {
+	body, err := Marshal(v)
+	if err != nil {
+		if b.err == nil {
+			b.err = fmt.Errorf("marshaling body: %w", err)
+		}
+		return b
+	}
+	b.r.Body = body
+	b.r.SetHeader("Content-Type", "application/json")
+	return b
+}
+
+// RawBody sets the request body as is.
+func (b *Builder) RawBody(body []byte) *Builder {
+	b.r.Body = body
+	return b
+}
+
+// Bearer sets the Authorization header to the bearer token.
+func (b *Builder) Bearer(token string) *Builder {
+	WithBearer(&b.r, token)
+	return b
+}
+
+// BasicAuth sets the Authorization header to use HTTP Basic authentication.
+func (b *Builder) BasicAuth(username, password string) *Builder {
+	WithBasicAuth(&b.r, username, password)
+	return b
+}
+
+// UserAgent sets the User-Agent header, taking precedence over [DefaultUserAgent].
+func (b *Builder) UserAgent(ua string) *Builder {
+	b.r.SetHeader("User-Agent", ua)
+	return b
+}
+
+// Accept sets the Accept header to the given media type.
+func (b *Builder) Accept(mediaType string) *Builder {
+	b.r.SetHeader("Accept", mediaType)
+	return b
+}
+
+// GzipBody makes [Builder.Build] compress the body using gzip, as done by [GzipBody]. It can be combined with
+// [Builder.JSONBody] in any order.
+func (b *Builder) GzipBody() *Builder {
+	b.gzip = true
+	return b
+}
+
+// Build returns the constructed [Request], or the first error encountered while building it.
+func (b *Builder) Build() (Request, error) {
+	if b.err != nil {
+		return Request{}, b.err
+	}
+	if b.gzip {
+		r := b.r.Clone()
+		err := GzipBody(&r)
+		if err != nil {
+			return Request{}, err
+		}
+		return r, nil
+	}
+	return b.r, nil
+}
@@ -0,0 +1,217 @@
+package request
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithIfNoneMatch sets the If-None-Match header of the request to etag, making it conditional on the resource having
+// changed.
+func WithIfNoneMatch(r *Request, etag string) {
+	r.SetHeader("If-None-Match", etag)
+}
+
+// WithIfModifiedSince sets the If-Modified-Since header of the request to t, making it conditional on the resource
+// having been modified after it.
+func WithIfModifiedSince(r *Request, t time.Time) {
+	r.SetHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// CacheValidator holds the validators of a previously received response, for making conditional requests with them.
+type CacheValidator struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// NewCacheValidator captures the ETag and Last-Modified headers of the response.
+func NewCacheValidator(resp *Response) CacheValidator {
+	v := CacheValidator{ETag: resp.Header.Get("ETag")}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		v.LastModified = t
+	}
+	return v
+}
+
+// Apply makes the request conditional, by setting the If-None-Match and If-Modified-Since headers from the captured
+// validators. Validators which are not present are skipped.
+func (v CacheValidator) Apply(r *Request) {
+	if v.ETag != "" {
+		WithIfNoneMatch(r, v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		WithIfModifiedSince(r, v.LastModified)
+	}
+}
+
+// CacheEntry is a response stored in a [Cache], along with the time it stops being fresh.
+type CacheEntry struct {
+	Response *Response
+	Expires  time.Time
+}
+
+// Cache stores responses for [SendCached]. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// SendCached is like [Send], but serves GET and HEAD requests from the cache while they are fresh, as indicated by the
+// Cache-Control max-age directive. Stale entries with an ETag or Last-Modified header are revalidated using a
+// conditional request; if the server responds with 304 Not Modified, the cached response is returned and refreshed.
+// Successful responses are stored unless Cache-Control forbids it. Other methods are sent without consulting the
+// cache.
+//
+// Entries are keyed by the method and the full URL, including query parameters. Returned responses are copies, safe
+// to modify by the caller.
+func SendCached(ctx context.Context, client *http.Client, r Request, cache Cache, opts ...Option) (*Response, error) {
+	if r.Method != GET && r.Method != HEAD && r.Method != "" {
+		return Send(ctx, client, r, opts...)
+	}
+	req, err := newRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	key := req.Method + " " + req.URL.String()
+
+	entry, ok := cache.Get(key)
+	if ok && time.Now().Before(entry.Expires) {
+		return copyResponse(entry.Response), nil
+	}
+
+	sent := r
+	v := CacheValidator{}
+	if ok {
+		v = NewCacheValidator(entry.Response)
+		sent = r.Clone()
+		v.Apply(&sent)
+	}
+
+	resp, err := Send(ctx, client, sent, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.NotModified() && (v.ETag != "" || !v.LastModified.IsZero()) {
+		cached := copyResponse(entry.Response)
+		for k, vv := range resp.Header {
+			cached.Header[k] = vv
+		}
+		if exp, store := expiry(cached.Header); store {
+			cache.Set(key, &CacheEntry{Response: copyResponse(cached), Expires: exp})
+		}
+		return cached, nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		if exp, store := expiry(resp.Header); store {
+			cache.Set(key, &CacheEntry{Response: copyResponse(resp), Expires: exp})
+		}
+	}
+	return resp, nil
+}
+
+// expiry returns the time a response with the given header stops being fresh. If the response must not be stored, or
+// storing it is pointless since it is neither fresh nor revalidatable, store is false.
+func expiry(h http.Header) (_ time.Time, store bool) {
+	var (
+		maxAge  time.Duration
+		noCache bool
+	)
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			switch strings.ToLower(name) {
+			case "no-store":
+				return time.Time{}, false
+			case "no-cache":
+				noCache = true
+			case "max-age":
+				if secs, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && secs > 0 {
+					maxAge = time.Duration(secs) * time.Second
+				}
+			}
+		}
+	}
+
+	if noCache {
+		maxAge = 0
+	}
+	if maxAge == 0 && h.Get("ETag") == "" && h.Get("Last-Modified") == "" {
+		return time.Time{}, false
+	}
+	return time.Now().Add(maxAge), true
+}
+
+// copyResponse returns a copy of resp which doesn't share its body or header.
+func copyResponse(resp *Response) *Response {
+	c := *resp
+	c.Body = bytes.Clone(resp.Body)
+	c.Header = resp.Header.Clone()
+	if c.Header == nil {
+		c.Header = make(http.Header)
+	}
+	return &c
+}
+
+// MemoryCache is an in-memory [Cache], evicting the least recently used entries once its capacity is reached. It must
+// be created with [NewMemoryCache], and is safe for concurrent use.
+type MemoryCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache returns a [MemoryCache] holding at most capacity entries. Values of capacity less than one are
+// treated as one.
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: max(capacity, 1),
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get implements [Cache].
+func (c *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*memoryCacheItem).entry, true
+}
+
+// Set implements [Cache].
+func (c *MemoryCache) Set(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*memoryCacheItem).entry = entry
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
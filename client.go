@@ -0,0 +1,96 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Client is a stateful layer on top of [Send] for talking to a single service. Request URLs are treated as paths
+// relative to BaseURL, and Header holds default headers added to every request. Headers explicitly set on a
+// [Request] take precedence over the defaults.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Header     http.Header
+}
+
+// ClientOption configures the [Client] created by [NewClient].
+type ClientOption func(*Client)
+
+// WithDefaultHeader adds the header to the defaults sent with every request of the [Client], e.g. for an API key or
+// Authorization. It can be passed multiple times, adding multiple values for the same key.
+func WithDefaultHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		c.Header.Add(key, value)
+	}
+}
+
+// NewClient returns a [Client] sending requests to baseURL through httpClient, configured by the options. If
+// httpClient is nil, the result of [DefaultClient] is used.
+func NewClient(baseURL string, httpClient *http.Client, opts ...ClientOption) *Client {
+	if httpClient == nil {
+		httpClient = DefaultClient(0)
+	}
+	c := &Client{
+		BaseURL:    baseURL,
+		HTTPClient: httpClient,
+		Header:     make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Do sends the request using [Send], after joining its URL with the base URL and adding the default headers.
+func (c *Client) Do(ctx context.Context, r Request, opts ...Option) (*Response, error) {
+	r.URL = c.url(r.URL)
+	r.Header = c.header(r.Header)
+	return Send(ctx, c.HTTPClient, r, opts...)
+}
+
+// Get sends a GET request to the given path.
+func (c *Client) Get(ctx context.Context, path string, opts ...Option) (*Response, error) {
+	return c.Do(ctx, Request{Method: GET, URL: path}, opts...)
+}
+
+// Post sends a POST request with the body to the given path.
+func (c *Client) Post(ctx context.Context, path string, body []byte, opts ...Option) (*Response, error) {
+	return c.Do(ctx, Request{Method: POST, URL: path, Body: body}, opts...)
+}
+
+// Put sends a PUT request with the body to the given path.
+func (c *Client) Put(ctx context.Context, path string, body []byte, opts ...Option) (*Response, error) {
+	return c.Do(ctx, Request{Method: PUT, URL: path, Body: body}, opts...)
+}
+
+// Patch sends a PATCH request with the body to the given path.
+func (c *Client) Patch(ctx context.Context, path string, body []byte, opts ...Option) (*Response, error) {
+	return c.Do(ctx, Request{Method: PATCH, URL: path, Body: body}, opts...)
+}
+
+// Delete sends a DELETE request to the given path.
+func (c *Client) Delete(ctx context.Context, path string, opts ...Option) (*Response, error) {
+	return c.Do(ctx, Request{Method: DELETE, URL: path}, opts...)
+}
+
+// url joins the base URL with path, making sure there is exactly one slash between them.
+func (c *Client) url(path string) string {
+	if path == "" {
+		return c.BaseURL
+	}
+	return strings.TrimRight(c.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// header returns a new header consisting of the default headers, overridden by the given ones.
+func (c *Client) header(h http.Header) http.Header {
+	if len(c.Header) == 0 {
+		return h
+	}
+	merged := c.Header.Clone()
+	for k, v := range h {
+		merged[http.CanonicalHeaderKey(k)] = v
+	}
+	return merged
+}
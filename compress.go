@@ -0,0 +1,99 @@
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// decompressorsMu guards decompressors, which may be extended by [RegisterDecompressor].
+var decompressorsMu sync.RWMutex
+
+// decompressors maps a content coding to a function creating its decompressing reader.
+var decompressors = map[string]func(io.Reader) (io.ReadCloser, error){
+	"gzip":   func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	"x-gzip": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	"deflate": func(r io.Reader) (io.ReadCloser, error) {
+		// deflate is supposed to be zlib-wrapped, but some servers send raw deflate streams.
+		br := bufio.NewReader(r)
+		h, _ := br.Peek(2)
+		if len(h) == 2 && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	},
+}
+
+// RegisterDecompressor makes responses of requests with Decompress set, whose Content-Encoding header includes coding,
+// be decoded by the reader fn creates. Coding is case-insensitive, and replaces any previously registered decompressor
+// for it, including the built-in gzip and deflate ones. It is meant to be called from init functions, such as by
+// packages adding support for more codings, e.g. Brotli or Zstandard, without forcing their dependencies on everyone.
+func RegisterDecompressor(coding string, fn func(io.Reader) (io.ReadCloser, error)) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors[strings.ToLower(coding)] = fn
+}
+
+// decompress decodes body according to the Content-Encoding header. Multiple codings are undone in reverse order of
+// their application. If any of the codings is unknown, body is returned as is with ok set to false. If limit is positive,
+// decompressed data larger than it results in [ErrBodyTooLarge].
+func decompress(header http.Header, body []byte, limit int64) (_ []byte, ok bool, err error) {
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+
+	var codings []string
+	for _, v := range header.Values("Content-Encoding") {
+		for _, c := range strings.Split(v, ",") {
+			c = strings.ToLower(strings.TrimSpace(c))
+			if c == "" || c == "identity" {
+				continue
+			}
+			if _, known := decompressors[c]; !known {
+				return body, false, nil
+			}
+			codings = append(codings, c)
+		}
+	}
+	if len(codings) == 0 {
+		return body, false, nil
+	}
+
+	for i := len(codings) - 1; i >= 0; i-- {
+		r, err := decompressors[codings[i]](bytes.NewReader(body))
+		if err != nil {
+			return nil, false, fmt.Errorf("decompressing %s: %w", codings[i], err)
+		}
+		body, err = readAll(r, limit)
+		r.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("decompressing %s: %w", codings[i], err)
+		}
+	}
+	return body, true, nil
+}
+
+// GzipBody compresses the request's Body using gzip, and sets the Content-Encoding header accordingly. The
+// Content-Length sent is that of the compressed body. It has no effect on [Request.BodyReader].
+func GzipBody(r *Request) error {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write(r.Body)
+	if err != nil {
+		return fmt.Errorf("compressing body: %w", err)
+	}
+	err = zw.Close()
+	if err != nil {
+		return fmt.Errorf("compressing body: %w", err)
+	}
+
+	r.Body = buf.Bytes()
+	r.SetHeader("Content-Encoding", "gzip")
+	return nil
+}
@@ -0,0 +1,32 @@
+// Package compressrequest adds Brotli and Zstandard decompression to the request module. It lives in a separate
+// module, so the compression dependencies are only pulled in by those who use it.
+package compressrequest
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/hossein1376/request"
+)
+
+// AcceptEncoding lists all the content codings supported once [Register] is called, suitable as the value of the
+// Accept-Encoding header. Since [http.Transport] only asks for gzip by itself, the header has to be set explicitly for
+// servers to use the other ones.
+const AcceptEncoding = "br, zstd, gzip, deflate"
+
+// Register makes the br and zstd content codings be decoded for requests with [request.Request.Decompress] set. It is
+// safe to call multiple times.
+func Register() {
+	request.RegisterDecompressor("br", func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(brotli.NewReader(r)), nil
+	})
+	request.RegisterDecompressor("zstd", func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	})
+}
@@ -0,0 +1,13 @@
+module github.com/hossein1376/request/compressrequest
+
+go 1.23.0
+
+replace github.com/hossein1376/request => ../
+
+require (
+	github.com/andybalholm/brotli v1.2.5
+	github.com/hossein1376/request v0.0.0-00010101000000-000000000000
+	github.com/klauspost/compress v1.18.4
+)
+
+require golang.org/x/text v0.22.0 // indirect
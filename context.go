@@ -0,0 +1,41 @@
+package request
+
+import (
+	"context"
+	"net/http"
+)
+
+// contextKey is the type of context keys defined by this module.
+type contextKey struct {
+	name string
+}
+
+func (k *contextKey) String() string {
+	return "request context value " + k.name
+}
+
+// HeaderContextKey is the context key under which [WithHeaders] stores headers. The associated value is of type
+// [http.Header].
+var HeaderContextKey = &contextKey{"headers"}
+
+// WithHeaders returns a copy of ctx carrying the headers, which are added to every request sent with it. It is useful
+// for values such as request IDs or auth tokens, which are known to middlewares rather than to the code sending the
+// request. Headers already present in ctx are kept, unless overridden by h.
+//
+// Headers explicitly set on a [Request] take precedence over the ones provided by the context.
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	merged := HeadersFromContext(ctx).Clone()
+	if merged == nil {
+		merged = make(http.Header, len(h))
+	}
+	for k, v := range h {
+		merged[http.CanonicalHeaderKey(k)] = v
+	}
+	return context.WithValue(ctx, HeaderContextKey, merged)
+}
+
+// HeadersFromContext returns the headers stored in ctx by [WithHeaders], or nil if there are none.
+func HeadersFromContext(ctx context.Context) http.Header {
+	h, _ := ctx.Value(HeaderContextKey).(http.Header)
+	return h
+}
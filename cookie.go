@@ -0,0 +1,19 @@
+package request
+
+import (
+	"context"
+	"net/http"
+)
+
+// SendWithJar is like [Send], but uses jar for storing the cookies set by the response and attaching them to the
+// request. It is equivalent to sending with a copy of client whose Jar field is set; setting [http.Client.Jar]
+// directly works the same way with every function of this package.
+//
+// Cookies in [Request.Cookies] are sent in addition to the ones provided by the jar, and never get stored in it.
+func SendWithJar(
+	ctx context.Context, client *http.Client, r Request, jar http.CookieJar, opts ...Option,
+) (*Response, error) {
+	c := *client
+	c.Jar = jar
+	return Send(ctx, &c, r, opts...)
+}
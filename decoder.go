@@ -0,0 +1,75 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// Marshal and Unmarshal are the JSON codec used by [NewJSON], [NewMergePatch], [NewJSONPatch], [Builder.JSONBody],
+// [JSONDecoder] and with it [SendParse], and [Response.JSON]. They default to [encoding/json], and can be replaced,
+// e.g. in an init function, to adopt a faster or stricter implementation. [StrictJSONDecoder] and [SendStreamJSON]
+// rely on features of [json.Decoder], and always use [encoding/json].
+var (
+	Marshal   func(v any) ([]byte, error)    = json.Marshal
+	Unmarshal func(data []byte, v any) error = json.Unmarshal
+)
+
+// Decoder decodes a response body into v. It is used by [SendParseWith] to support formats other than JSON.
+//
+// If a Decoder also has a MediaType() string method, its result is sent as the Accept header of requests which don't
+// already have one.
+type Decoder interface {
+	Decode(data []byte, v any) error
+}
+
+// JSONDecoder is a [Decoder] based on [Unmarshal].
+type JSONDecoder struct{}
+
+// Decode implements [Decoder].
+func (JSONDecoder) Decode(data []byte, v any) error {
+	return Unmarshal(data, v)
+}
+
+// MediaType returns application/json.
+func (JSONDecoder) MediaType() string {
+	return "application/json"
+}
+
+// StrictJSONDecoder is a [Decoder] rejecting JSON objects with fields not defined by the target type, as well as any
+// data following the JSON value. It is mostly useful for catching API drift in tests.
+type StrictJSONDecoder struct{}
+
+// Decode implements [Decoder].
+func (StrictJSONDecoder) Decode(data []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	err := d.Decode(v)
+	if err != nil {
+		return err
+	}
+	if _, err = d.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// MediaType returns application/json.
+func (StrictJSONDecoder) MediaType() string {
+	return "application/json"
+}
+
+// XMLDecoder is a [Decoder] based on [xml.Unmarshal].
+type XMLDecoder struct{}
+
+// Decode implements [Decoder].
+func (XMLDecoder) Decode(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// MediaType returns application/xml.
+func (XMLDecoder) MediaType() string {
+	return "application/xml"
+}
@@ -0,0 +1,194 @@
+package request
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// SendDigest is like [Send], but authenticates using HTTP Digest authentication as described by RFC 7616. The request
+// is first sent as is; if the server responds with 401 and a Digest challenge, the response to the challenge is
+// computed and the request is sent again with the Authorization header. The MD5 and SHA-256 algorithms, including
+// their -sess variants, and the auth and auth-int qop values are supported.
+//
+// Since the request may be sent twice, it must use Body rather than [Request.BodyReader]; with a BodyReader, which is
+// used up by the first send, the challenge is not answered and its response is returned as is.
+func SendDigest(
+	ctx context.Context, client *http.Client, r Request, username, password string, opts ...Option,
+) (*Response, error) {
+	resp, err := Send(ctx, client, r, opts...)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || r.BodyReader != nil {
+		return resp, err
+	}
+
+	c, ok := parseDigestChallenge(resp.Header)
+	if !ok {
+		return resp, nil
+	}
+	req, err := newRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	auth, err := c.authorize(req.Method, req.URL.RequestURI(), r.Body, username, password, hex.EncodeToString(b[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	r = r.Clone()
+	r.SetHeader("Authorization", auth)
+	return Send(ctx, client, r, opts...)
+}
+
+// digestChallenge holds the parameters of a WWW-Authenticate Digest challenge.
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       []string
+}
+
+// parseDigestChallenge finds and parses the Digest challenge among the WWW-Authenticate headers.
+func parseDigestChallenge(h http.Header) (digestChallenge, bool) {
+	for _, v := range h.Values("WWW-Authenticate") {
+		scheme, rest, _ := strings.Cut(strings.TrimSpace(v), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+
+		params := parseAuthParams(rest)
+		c := digestChallenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+		}
+		for _, q := range strings.Split(params["qop"], ",") {
+			if q = strings.TrimSpace(q); q != "" {
+				c.qop = append(c.qop, q)
+			}
+		}
+		if c.nonce == "" {
+			continue
+		}
+		return c, true
+	}
+	return digestChallenge{}, false
+}
+
+// parseAuthParams parses a comma separated list of key=value pairs, where values may be quoted strings.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		s = strings.TrimLeft(s, " \t,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(rest, `"`) {
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			v, after, _ := strings.Cut(rest, ",")
+			value.WriteString(strings.TrimSpace(v))
+			s = after
+		}
+		params[key] = value.String()
+	}
+	return params
+}
+
+// authorize computes the value of the Authorization header answering the challenge, using the given client nonce.
+func (c digestChallenge) authorize(method, uri string, body []byte, username, password, cnonce string) (string, error) {
+	algorithm := c.algorithm
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm: %q", algorithm)
+	}
+	h := func(s string) string {
+		d := newHash()
+		d.Write([]byte(s))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	var qop string
+	switch {
+	case len(c.qop) == 0:
+	case containsFold(c.qop, "auth"):
+		qop = "auth"
+	case containsFold(c.qop, "auth-int"):
+		qop = "auth-int"
+	default:
+		return "", fmt.Errorf("unsupported digest qop: %q", strings.Join(c.qop, ","))
+	}
+
+	// Every challenge is answered once, so this is always the first use of the nonce.
+	const nc = "00000001"
+
+	ha1 := h(username + ":" + c.realm + ":" + password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+	if qop == "auth-int" {
+		ha2 = h(method + ":" + uri + ":" + h(string(body)))
+	}
+
+	var response string
+	if qop == "" {
+		response = h(ha1 + ":" + c.nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `Digest username=%s, realm=%s, nonce=%s, uri=%s, algorithm=%s, response=%s`,
+		quote(username), quote(c.realm), quote(c.nonce), quote(uri), algorithm, quote(response))
+	if c.opaque != "" {
+		fmt.Fprintf(&sb, `, opaque=%s`, quote(c.opaque))
+	}
+	if qop != "" {
+		fmt.Fprintf(&sb, `, qop=%s, nc=%s, cnonce=%s`, qop, nc, quote(cnonce))
+	}
+	return sb.String(), nil
+}
+
+// quote returns s as an HTTP quoted-string.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
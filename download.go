@@ -0,0 +1,212 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxErrorBody is the maximum amount of an unacceptable response's body kept in [StatusError] by streaming
+	// functions.
+	maxErrorBody = 64 << 10
+	// progressInterval is the minimum time between two calls of a progress callback.
+	progressInterval = 100 * time.Millisecond
+)
+
+// ResponseMeta consists of the HTTP response data, excluding its body.
+type ResponseMeta struct {
+	Header     http.Header
+	Cookies    []*http.Cookie
+	StatusCode int
+}
+
+// Download sends an HTTP request based on the [Request], and copies the response body to dst without buffering it
+// in memory. It returns the number of bytes written, along with the response's metadata.
+//
+// Optionally, caller can provide a number of acceptable status codes. If the response's status code is not in them,
+// nothing is written to dst and a [*StatusError] holding up to the first 64 KiB of the body is returned. If copying
+// fails midway, dst may contain partial content.
+func Download(
+	ctx context.Context, client *http.Client, r Request, dst io.Writer, acceptable ...int,
+) (int64, *ResponseMeta, error) {
+	return download(ctx, client, r, dst, nil, acceptable)
+}
+
+// DownloadProgress is like [Download], but also calls progress as the data is written to dst. total is taken from the
+// Content-Length header, and is -1 if it is unknown. progress is called at most once every 100 milliseconds, and once
+// more after the copy is done.
+func DownloadProgress(
+	ctx context.Context,
+	client *http.Client,
+	r Request,
+	dst io.Writer,
+	progress func(written, total int64),
+	acceptable ...int,
+) (int64, *ResponseMeta, error) {
+	return download(ctx, client, r, dst, progress, acceptable)
+}
+
+func download(
+	ctx context.Context,
+	client *http.Client,
+	r Request,
+	dst io.Writer,
+	progress func(written, total int64),
+	acceptable []int,
+) (int64, *ResponseMeta, error) {
+	resp, err := SendRaw(ctx, client, r)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	meta := &ResponseMeta{
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		StatusCode: resp.StatusCode,
+	}
+	if len(acceptable) != 0 && !slices.Contains(acceptable, resp.StatusCode) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return 0, meta, &StatusError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
+	}
+
+	if progress != nil {
+		pw := &progressWriter{w: dst, total: resp.ContentLength, fn: progress, last: time.Now()}
+		defer func() { progress(pw.written, pw.total) }()
+		dst = pw
+	}
+
+	n, err := io.Copy(dst, resp.Body)
+	if err != nil {
+		return n, meta, fmt.Errorf("copying response: %w", err)
+	}
+	return n, meta, nil
+}
+
+// ResumeDownload downloads url into dst, resuming an interrupted download by only requesting the bytes following the
+// current content of dst, using the Range header; dst must be opened for writing. It returns the number of bytes written
+// by this call, along with the response's metadata.
+//
+// If the server supports ranges and answers with the 206 status code, the remaining bytes are appended to dst. If it
+// ignores the header and sends the whole content with the 200 status code, dst is truncated and fully rewritten. If
+// dst already holds the whole content, as reported by a 416 status code, nothing is written. Any other status code
+// results in a [*StatusError]. If copying fails midway, dst holds the content received so far, and calling
+// ResumeDownload again continues from there.
+func ResumeDownload(ctx context.Context, client *http.Client, url string, dst *os.File) (int64, *ResponseMeta, error) {
+	info, err := dst.Stat()
+	if err != nil {
+		return 0, nil, fmt.Errorf("getting file info: %w", err)
+	}
+	offset := info.Size()
+
+	r := Request{Method: GET, URL: url}
+	if offset > 0 {
+		r.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := SendRaw(ctx, client, r)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	meta := &ResponseMeta{
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		StatusCode: resp.StatusCode,
+	}
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, _, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return 0, meta, fmt.Errorf("resuming download: unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		_, err = dst.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, meta, fmt.Errorf("seeking file: %w", err)
+		}
+	case resp.StatusCode == http.StatusOK:
+		err = dst.Truncate(0)
+		if err != nil {
+			return 0, meta, fmt.Errorf("truncating file: %w", err)
+		}
+		_, err = dst.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, meta, fmt.Errorf("seeking file: %w", err)
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		_, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if ok && size == offset {
+			return 0, meta, nil
+		}
+		fallthrough
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return 0, meta, &StatusError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
+	}
+
+	n, err := io.Copy(dst, resp.Body)
+	if err != nil {
+		return n, meta, fmt.Errorf("copying response: %w", err)
+	}
+	return n, meta, nil
+}
+
+// parseContentRange parses a Content-Range header of the form "bytes start-end/size" or "bytes */size". start is -1
+// for the latter, and size is -1 if it is unknown.
+func parseContentRange(v string) (start, size int64, ok bool) {
+	rng, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, total, found := strings.Cut(strings.TrimSpace(rng), "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	size = -1
+	if total != "*" {
+		n, err := strconv.ParseInt(total, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		size = n
+	}
+	if rng == "*" {
+		return -1, size, true
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// progressWriter reports the progress of writes to w by calling fn periodically.
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	total   int64
+	fn      func(written, total int64)
+	last    time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.fn(p.written, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,92 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"strings"
+)
+
+// DumpRequest renders the request the way it would be sent over the wire, as done by [httputil.DumpRequestOut],
+// including its body. It is useful for debugging, and reproducing issues. If the request uses
+// [Request.BodyReader], dumping it consumes the reader.
+func DumpRequest(r Request) (string, error) {
+	req, err := newRequest(context.Background(), r)
+	if err != nil {
+		return "", err
+	}
+	b, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return "", fmt.Errorf("dumping request: %w", err)
+	}
+	return string(b), nil
+}
+
+// ToCurl returns a curl command equivalent to the request, with its final URL, headers, cookies and body. Headers
+// added by the transport itself, such as User-Agent, are not included unless set explicitly or by
+// [DefaultUserAgent]. A body provided by [Request.BodyReader] is not included, since reading it would consume it. HEAD
+// requests use the -I flag.
+func ToCurl(r Request) string {
+	url, header := r.URL, r.Header
+	if req, err := newRequest(context.Background(), r); err == nil {
+		url, header = req.URL.String(), req.Header
+	}
+
+	var sb strings.Builder
+	sb.WriteString("curl")
+	switch r.Method {
+	case "", GET:
+	case HEAD:
+		// -X HEAD would make curl wait for a body that never comes.
+		sb.WriteString(" -I")
+	default:
+		sb.WriteString(" -X " + shellQuote(r.Method))
+	}
+	sb.WriteString(" " + shellQuote(url))
+
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			sb.WriteString(" -H " + shellQuote(k+": "+v))
+		}
+	}
+
+	if r.BodyReader == nil && len(r.Body) != 0 {
+		sb.WriteString(" --data-binary " + shellQuote(string(r.Body)))
+	}
+	return sb.String()
+}
+
+// shellQuote quotes s for use as a single argument in POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// redacted replaces the values of headers redacted by [RedactHeaders].
+const redacted = "***"
+
+// RedactHeaders returns a copy of h, with the values of the headers named by keys replaced by ***, e.g. for logging
+// requests in [Hooks] without leaking secrets. Keys are case-insensitive. If none are given, Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie are redacted.
+func RedactHeaders(h http.Header, keys ...string) http.Header {
+	if len(keys) == 0 {
+		keys = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	}
+
+	c := h.Clone()
+	for k, vs := range c {
+		if !slices.ContainsFunc(keys, func(key string) bool { return strings.EqualFold(k, key) }) {
+			continue
+		}
+		for i := range vs {
+			vs[i] = redacted
+		}
+	}
+	return c
+}
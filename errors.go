@@ -0,0 +1,83 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+var (
+	// ErrTransport is wrapped by errors which happen while exchanging the request and response with the server, such
+	// as DNS failures, refused connections or dropped connections.
+	ErrTransport = errors.New("transport error")
+	// ErrUnacceptableStatus is matched by [*StatusError] using [errors.Is].
+	ErrUnacceptableStatus = errors.New("unacceptable status code")
+	// ErrDecode is matched by [*DecodeError] using [errors.Is].
+	ErrDecode = errors.New("decoding response")
+	// ErrBodyTooLarge is returned when the response body exceeds [Request.MaxBodySize].
+	ErrBodyTooLarge = errors.New("response body too large")
+	// ErrNoLocation is returned when a redirect target is needed, but the response has no Location header.
+	ErrNoLocation = errors.New("response has no Location header")
+)
+
+// StatusError is returned when the response's status code is not an acceptable one. It carries the response body and
+// header, so the caller can inspect or decode the error payload sent by the server.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+	Header     http.Header
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unacceptable status code: %d", e.StatusCode)
+}
+
+// Is reports whether target is [ErrUnacceptableStatus].
+func (e *StatusError) Is(target error) bool {
+	return target == ErrUnacceptableStatus
+}
+
+// maxSnippet is the maximum length of the body snippet included in error messages.
+const maxSnippet = 200
+
+// DecodeError is returned when the response body can't be decoded, e.g. because the server returned an HTML error
+// page instead of JSON. It carries the status code, along with the full body and header, so the caller can still
+// inspect or log the response that failed to decode; its message includes a truncated snippet of the body.
+type DecodeError struct {
+	StatusCode int
+	Body       []byte
+	Header     http.Header
+	Err        error
+}
+
+func (e *DecodeError) Error() string {
+	snippet := e.Body
+	suffix := ""
+	if len(snippet) > maxSnippet {
+		snippet = snippet[:maxSnippet]
+		suffix = "..."
+	}
+	return fmt.Sprintf("decoding response with status code %d: %v; body: %q%s", e.StatusCode, e.Err, snippet, suffix)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is [ErrDecode].
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// IsTimeout reports whether err is caused by a timeout: either the deadline of a context or the Timeout of an
+// [http.Client] being exceeded, both of which match [context.DeadlineExceeded], or a lower level one reported by a
+// [net.Error], such as a dial or TLS handshake timeout. Cancellation of a context is not considered a timeout.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
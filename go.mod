@@ -1,3 +1,5 @@
 module github.com/hossein1376/request
 
-go 1.22
+go 1.23
+
+require golang.org/x/text v0.22.0
@@ -0,0 +1,23 @@
+package request
+
+import (
+	"context"
+	"net/http"
+)
+
+// Head sends a HEAD request to url, and returns the response's metadata. It is suitable for existence checks and
+// reading headers such as Content-Length, Last-Modified or ETag, without dealing with a body.
+func Head(ctx context.Context, client *http.Client, url string, opts ...Option) (*ResponseMeta, error) {
+	resp, err := SendRaw(ctx, client, Request{Method: HEAD, URL: url}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	meta := &ResponseMeta{
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		StatusCode: resp.StatusCode,
+	}
+	return meta, nil
+}
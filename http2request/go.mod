@@ -0,0 +1,7 @@
+module github.com/hossein1376/request/http2request
+
+go 1.23.0
+
+require golang.org/x/net v0.43.0
+
+require golang.org/x/text v0.28.0 // indirect
@@ -0,0 +1,62 @@
+// Package http2request provides clients which explicitly use HTTP/2, including h2c, based on golang.org/x/net/http2.
+// It lives in a separate module, so the x/net dependency is only pulled in by those who use it.
+package http2request
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultDialTimeout = 10 * time.Second
+)
+
+// Options configures the clients created by [ClientHTTP2] and [ClientH2C].
+//
+// Timeout is the limit for the whole exchange, including reading the response body; if it is not positive, 30 seconds
+// is used. TLSConfig is used for TLS connections, and has no effect on h2c. If ReadIdleTimeout is positive, a ping
+// frame is sent when no frame has been received for that long, and the connection is closed if no response arrives
+// within PingTimeout.
+type Options struct {
+	Timeout         time.Duration
+	TLSConfig       *tls.Config
+	ReadIdleTimeout time.Duration
+	PingTimeout     time.Duration
+}
+
+// ClientHTTP2 returns an [http.Client] which always uses HTTP/2 over TLS, instead of relying on ALPN negotiation to
+// pick it. Servers not supporting HTTP/2 can't be reached with it.
+func ClientHTTP2(opts Options) *http.Client {
+	return newClient(opts, &http2.Transport{
+		TLSClientConfig: opts.TLSConfig,
+	})
+}
+
+// ClientH2C returns an [http.Client] which uses HTTP/2 over cleartext TCP, also known as h2c, with prior knowledge.
+// It is meant for internal services; requests must use http URLs.
+func ClientH2C(opts Options) *http.Client {
+	dialer := &net.Dialer{Timeout: defaultDialTimeout}
+	return newClient(opts, &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+	})
+}
+
+func newClient(opts Options, t *http2.Transport) *http.Client {
+	t.ReadIdleTimeout = opts.ReadIdleTimeout
+	t.PingTimeout = opts.PingTimeout
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Transport: t, Timeout: timeout}
+}
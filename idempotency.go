@@ -0,0 +1,28 @@
+package request
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// WithIdempotencyKey sets the Idempotency-Key header of the request, and returns the key so that it can be logged. If
+// key is empty, a random UUID version 4 is generated and used.
+//
+// The key is part of the request, so sending it multiple times, e.g. by [SendRetry], reuses the same key for all the
+// attempts.
+func WithIdempotencyKey(r *Request, key string) string {
+	if key == "" {
+		key = newUUID()
+	}
+	r.SetHeader("Idempotency-Key", key)
+	return key
+}
+
+// newUUID returns a random UUID version 4, as specified by RFC 9562.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,29 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Limiter throttles outgoing requests. It is satisfied by *rate.Limiter of golang.org/x/time/rate, without this module
+// depending on it.
+type Limiter interface {
+	// Wait blocks until the request is allowed to proceed, or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// SendLimited is like [Send], but waits on the limiter before sending the request. Sharing the same limiter between
+// calls throttles all of them together. If ctx is cancelled while waiting, the context's error is returned.
+func SendLimited(
+	ctx context.Context, client *http.Client, r Request, limiter Limiter, opts ...Option,
+) (*Response, error) {
+	err := limiter.Wait(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("waiting for limiter: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("waiting for limiter: %w", err)
+	}
+	return Send(ctx, client, r, opts...)
+}
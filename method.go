@@ -1,5 +1,9 @@
 package request
 
+import (
+	"slices"
+)
+
 // Method defines all HTTP verbs.
 type Method = string
 
@@ -14,3 +18,12 @@ const (
 	TRACE   Method = "TRACE"
 	CONNECT Method = "CONNECT"
 )
+
+// ValidMethods lists all the HTTP verbs known to this module.
+var ValidMethods = []Method{GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS, TRACE, CONNECT}
+
+// IsValidMethod reports whether m is one of [ValidMethods]. Since [Method] is an alias of string, this can't be a
+// method on it.
+func IsValidMethod(m Method) bool {
+	return slices.Contains(ValidMethods, m)
+}
@@ -0,0 +1,39 @@
+package request
+
+import (
+	"net/url"
+	"time"
+)
+
+// Metrics receives observations about sent requests, decoupling this module from any metrics library such as
+// Prometheus or StatsD. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest is called once per request sent by [Send]. status is zero if no response was received, in which
+	// case err is not nil.
+	ObserveRequest(method, host string, status int, dur time.Duration, err error)
+}
+
+// NopMetrics is a [Metrics] discarding all observations. It is used when no other one is provided.
+type NopMetrics struct{}
+
+// ObserveRequest implements [Metrics].
+func (NopMetrics) ObserveRequest(string, string, int, time.Duration, error) {}
+
+// WithMetrics makes [Send] report every request to m. A nil m disables reporting.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		if m == nil {
+			m = NopMetrics{}
+		}
+		o.metrics = m
+	}
+}
+
+// hostOf returns the host of rawURL, or an empty string if it can't be parsed.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
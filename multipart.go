@@ -0,0 +1,111 @@
+package request
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// MultipartBody holds the fields and files of a multipart/form-data body. It must be created with [NewMultipart], and
+// turned into a [Request] by calling [MultipartBody.Request].
+type MultipartBody struct {
+	parts []multipartPart
+}
+
+type multipartPart struct {
+	field    string
+	filename string
+	value    string
+	content  io.Reader
+}
+
+// NewMultipart returns an empty [MultipartBody].
+func NewMultipart() *MultipartBody {
+	return &MultipartBody{}
+}
+
+// Field adds a text field to the body.
+func (m *MultipartBody) Field(name, value string) *MultipartBody {
+	m.parts = append(m.parts, multipartPart{field: name, value: value})
+	return m
+}
+
+// File adds a file part to the body, reading its content from the given reader.
+func (m *MultipartBody) File(field, filename string, content io.Reader) *MultipartBody {
+	m.parts = append(m.parts, multipartPart{field: field, filename: filename, content: content})
+	return m
+}
+
+// FileBytes adds a file part to the body with the given content.
+func (m *MultipartBody) FileBytes(field, filename string, content []byte) *MultipartBody {
+	return m.File(field, filename, bytes.NewReader(content))
+}
+
+// Request creates a [Request] with the encoded multipart body, and sets the Content-Type header including the
+// boundary. All file contents are read into memory; see [MultipartBody.StreamRequest] for avoiding that.
+func (m *MultipartBody) Request(method Method, url string) (Request, error) {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	err := m.write(mw)
+	if err != nil {
+		return Request{}, err
+	}
+
+	r := Request{
+		Method: method,
+		URL:    url,
+		Header: http.Header{"Content-Type": {mw.FormDataContentType()}},
+		Body:   buf.Bytes(),
+	}
+	return r, nil
+}
+
+// StreamRequest is like [MultipartBody.Request], but the body is streamed through [Request.BodyReader] instead of
+// being buffered, which is preferable for large files. The parts are encoded while the request is being sent, so any
+// of their errors is reported by the sending function. The returned request must be sent exactly once; if sending
+// fails before the body is read, e.g. due to a done context, the body is closed and encoding stops.
+func (m *MultipartBody) StreamRequest(method Method, url string) Request {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	r := Request{
+		Method:     method,
+		URL:        url,
+		Header:     http.Header{"Content-Type": {mw.FormDataContentType()}},
+		BodyReader: pr,
+	}
+
+	go func() {
+		pw.CloseWithError(m.write(mw))
+	}()
+	return r
+}
+
+// write encodes all the parts into mw, and closes it.
+func (m *MultipartBody) write(mw *multipart.Writer) error {
+	for _, p := range m.parts {
+		if p.content == nil {
+			err := mw.WriteField(p.field, p.value)
+			if err != nil {
+				return fmt.Errorf("writing field %q: %w", p.field, err)
+			}
+			continue
+		}
+
+		w, err := mw.CreateFormFile(p.field, p.filename)
+		if err != nil {
+			return fmt.Errorf("creating file %q: %w", p.filename, err)
+		}
+		_, err = io.Copy(w, p.content)
+		if err != nil {
+			return fmt.Errorf("writing file %q: %w", p.filename, err)
+		}
+	}
+
+	err := mw.Close()
+	if err != nil {
+		return fmt.Errorf("closing multipart writer: %w", err)
+	}
+	return nil
+}
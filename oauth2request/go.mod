@@ -0,0 +1,12 @@
+module github.com/hossein1376/request/oauth2request
+
+go 1.23.0
+
+replace github.com/hossein1376/request => ../
+
+require (
+	github.com/hossein1376/request v0.0.0-00010101000000-000000000000
+	golang.org/x/oauth2 v0.30.0
+)
+
+require golang.org/x/text v0.22.0 // indirect
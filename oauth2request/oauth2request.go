@@ -0,0 +1,41 @@
+// Package oauth2request integrates the request module with golang.org/x/oauth2. It lives in a separate module, so the
+// oauth2 dependency is only pulled in by those who use it.
+package oauth2request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+
+	"github.com/hossein1376/request"
+)
+
+// ErrToken is wrapped by errors returned when obtaining or refreshing a token fails, so that they can be told apart
+// from other failures of [request.Send] using [errors.Is].
+var ErrToken = errors.New("obtaining oauth2 token")
+
+// ClientWithTokenSource returns an [http.Client] which attaches a bearer token from ts to every request, refreshing
+// it as needed. Other than that, the client is the same as the one returned by [request.DefaultClient].
+func ClientWithTokenSource(ts oauth2.TokenSource) *http.Client {
+	client := request.DefaultClient(0)
+	client.Transport = &oauth2.Transport{
+		Source: oauth2.ReuseTokenSource(nil, tokenSource{ts}),
+		Base:   client.Transport,
+	}
+	return client
+}
+
+// tokenSource wraps the errors of a [oauth2.TokenSource] with [ErrToken].
+type tokenSource struct {
+	ts oauth2.TokenSource
+}
+
+func (s tokenSource) Token() (*oauth2.Token, error) {
+	t, err := s.ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrToken, err)
+	}
+	return t, nil
+}
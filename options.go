@@ -0,0 +1,92 @@
+package request
+
+import (
+	"net/http"
+)
+
+// Option configures the behavior of [Send] and the functions built on top of it.
+type Option func(*options)
+
+type options struct {
+	hooks          []Hooks
+	signer         Signer
+	metrics        Metrics
+	timings        bool
+	validateMethod bool
+	customMethods  []Method
+	expectContinue bool
+
+	// collector is set by sendRaw when timings is true.
+	collector *timingsCollector
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{metrics: NopMetrics{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Hooks are callbacks invoked around sending a request, mainly useful for logging and observability.
+//
+// BeforeSend is called with the request right before it is sent. It may intentionally alter the request; changes only
+// affect the current call, but note that maps such as Header are shared with the caller.
+// AfterSend is called once the request is done, with its response or error.
+// OnRequest is called with the final [http.Request] right before it is handed to the client, e.g. for injecting trace
+// context propagation headers.
+//
+// Since BeforeSend and AfterSend deal with the buffered [Response], they are only called by [Send] and the functions
+// built on top of it. OnRequest is also called by [SendRaw] and [SendStream].
+type Hooks struct {
+	BeforeSend func(*Request)
+	AfterSend  func(*Request, *Response, error)
+	OnRequest  func(*http.Request)
+}
+
+// WithHooks registers the hooks. It can be passed multiple times, in which case the hooks are called in order.
+func WithHooks(h Hooks) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, h)
+	}
+}
+
+// ValidateMethod makes [Send] return an error for a request whose method is not one of [ValidMethods], catching typos
+// before anything is sent. An empty method is accepted as GET. Extra methods, such as WebDAV's PROPFIND, can be
+// explicitly allowed by passing them.
+func ValidateMethod(custom ...Method) Option {
+	return func(o *options) {
+		o.validateMethod = true
+		o.customMethods = append(o.customMethods, custom...)
+	}
+}
+
+// WithExpectContinue sends requests with the "Expect: 100-continue" header, so that the server can reject them based on
+// their headers, e.g. for failed authentication or an excessive size, before the body is uploaded. The client waits
+// up to its transport's ExpectContinueTimeout for the server's interim response, then sends the body anyway; that
+// timeout must be positive for the header to have any effect. Clients created by [DefaultClient] wait a second, and
+// [ClientPool] allows tuning it.
+//
+// This is only meaningful for large bodies, which should be provided as a streaming BodyReader; a buffered Body is
+// already held in memory, and small ones are usually sent before any answer could arrive. Requests without a body are
+// sent without the header.
+func WithExpectContinue() Option {
+	return func(o *options) {
+		o.expectContinue = true
+	}
+}
+
+// Signer signs requests, e.g. using AWS SigV4 or HMAC schemes. Sign may modify the request, typically by adding
+// headers. For requests with a Body, [http.Request.GetBody] can be used to read it without consuming it.
+type Signer interface {
+	Sign(req *http.Request) error
+}
+
+// WithSigner makes the signer sign every request. It is called right before the request is handed to the client,
+// after the URL and the headers are finalized and all the OnRequest hooks have run, so that the signature covers the
+// request exactly as it is sent.
+func WithSigner(s Signer) Option {
+	return func(o *options) {
+		o.signer = s
+	}
+}
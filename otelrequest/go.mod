@@ -0,0 +1,19 @@
+module github.com/hossein1376/request/otelrequest
+
+go 1.23.0
+
+replace github.com/hossein1376/request => ../
+
+require (
+	github.com/hossein1376/request v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.38.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
@@ -0,0 +1,59 @@
+// Package otelrequest integrates the request module with OpenTelemetry. It lives in a separate module, so the
+// OpenTelemetry dependencies are only pulled in by those who use it.
+package otelrequest
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hossein1376/request"
+)
+
+const tracerName = "github.com/hossein1376/request/otelrequest"
+
+// SendTraced is like [request.Send], but wraps the call in a client span created by the global tracer provider. The
+// trace context is injected into the outgoing request using the global propagator, e.g. as the traceparent and
+// tracestate headers. The response status code and any error are recorded on the span. As with [request.Send], a
+// partial response may be returned along with an error.
+func SendTraced(
+	ctx context.Context, client *http.Client, r request.Request, opts ...request.Option,
+) (*request.Response, error) {
+	ctx, span := otel.Tracer(tracerName).Start(
+		ctx,
+		"HTTP "+r.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.full", r.URL),
+		),
+	)
+	defer span.End()
+
+	// Copy the options, so that appending doesn't write into the caller's backing array.
+	opts = append(slices.Clip(opts), request.WithHooks(request.Hooks{OnRequest: Inject}))
+	resp, err := request.Send(ctx, client, r, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.IsServerError() {
+		span.SetStatus(codes.Error, resp.Status())
+	}
+	return resp, nil
+}
+
+// Inject injects the trace context of req's context into its headers using the global propagator. It can be used as
+// [request.Hooks.OnRequest] for propagating traces without creating spans.
+func Inject(req *http.Request) {
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+}
@@ -0,0 +1,113 @@
+package request
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Paginate returns an iterator over the pages of a paginated API. Starting with initial, each response is decoded as
+// JSON into a new T and yielded, then next is called with the response to obtain the request for the following page.
+// Iteration ends once next returns false, the caller stops, or an error is yielded.
+//
+// Responses with a non-2xx status code yield a [*StatusError], and those failing to decode yield a [*DecodeError].
+// [NextLink] provides a next function for APIs using the Link header.
+func Paginate[T any](
+	ctx context.Context, client *http.Client, initial Request, next func(*Response) (Request, bool),
+) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		r := initial
+		for {
+			resp, err := Send(ctx, client, r)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !resp.IsSuccess() {
+				yield(nil, &StatusError{StatusCode: resp.StatusCode, Body: resp.Body, Header: resp.Header})
+				return
+			}
+
+			t := new(T)
+			err = JSONDecoder{}.Decode(resp.Body, t)
+			if err != nil {
+				yield(nil, &DecodeError{StatusCode: resp.StatusCode, Body: resp.Body, Header: resp.Header, Err: err})
+				return
+			}
+			if !yield(t, nil) {
+				return
+			}
+
+			var ok bool
+			r, ok = next(resp)
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// NextLink returns a next function for [Paginate], following the Link header's rel="next" target until a response
+// doesn't have one. Requests for the following pages are clones of template with their URL set to the link, resolved
+// against the response's URL. Since the link already includes the query string, the template's parameters and
+// RawQuery are not used for them.
+func NextLink(template Request) func(*Response) (Request, bool) {
+	return func(resp *Response) (Request, bool) {
+		link, ok := parseLinks(resp.Header)["next"]
+		if !ok {
+			return Request{}, false
+		}
+		base, err := url.Parse(resp.FinalURL)
+		if err != nil {
+			return Request{}, false
+		}
+		u, err := base.Parse(link)
+		if err != nil {
+			return Request{}, false
+		}
+
+		r := template.Clone()
+		r.URL = u.String()
+		r.Params = nil
+		r.ParamsMulti = nil
+		r.RawQuery = ""
+		return r, true
+	}
+}
+
+// parseLinks parses the Link headers as described by RFC 8288, returning the target of each relation type.
+func parseLinks(h http.Header) map[string]string {
+	links := make(map[string]string)
+	for _, v := range h.Values("Link") {
+		for v != "" {
+			start := strings.IndexByte(v, '<')
+			end := strings.IndexByte(v, '>')
+			if start < 0 || end < start {
+				break
+			}
+			target := v[start+1 : end]
+			v = v[end+1:]
+
+			params := v
+			if i := strings.IndexByte(v, ','); i >= 0 {
+				params, v = v[:i], v[i+1:]
+			} else {
+				v = ""
+			}
+			for _, p := range strings.Split(params, ";") {
+				key, val, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if !strings.EqualFold(key, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(val, `"`)) {
+					if _, ok := links[strings.ToLower(rel)]; !ok {
+						links[strings.ToLower(rel)] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
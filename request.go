@@ -2,93 +2,484 @@ package request
 
 import (
 	"bytes"
+	"cmp"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
 	"slices"
+	"strconv"
+	"time"
 )
 
+// DefaultUserAgent is used as the User-Agent header of requests which don't explicitly set one. If it is empty, the
+// default of [net/http] is used.
+var DefaultUserAgent = ""
+
 // Request includes all the necessary data for creating an HTTP request. Method can be a string; or be one of the
 // predefined ones by this module. URL must be the full address with all the prefix and suffixes.
 // Header, Cookies and Body are not mandatory and might be filled based on the requirements.
-// Params is a map for providing URL-encoded query parameters.
+// BodyReader can be set instead of Body for streaming the request body without buffering it; when it is not nil, Body
+// is ignored. Since a reader can only be consumed once, it is not suitable for requests sent more than once; e.g.
+// [SendRetry] sends such a request only once.
+// ContentLength, if positive, is the length of the BodyReader's content. A BodyReader of unknown length is sent using
+// chunked transfer encoding, which some servers, such as S3, reject; setting it makes the upload non-chunked while
+// still streaming it. The reader must provide exactly that many bytes, otherwise sending fails.
+// Params is a map for providing URL-encoded query parameters. ParamsMulti can be used alongside it for keys with
+// multiple values, e.g. ?id=1&id=2. Query parameters are encoded sorted by key, values retaining their order. Their
+// keys and values must be raw, not URL-encoded, since they are always encoded; reserved characters such as +, & or
+// spaces are escaped and reach the server unchanged, whereas an already encoded value, e.g. a%2Bb, is encoded twice.
+// Adding parameters re-encodes the query already present in URL; without any, it is sent exactly as written.
+// RawQuery, if not empty, is used verbatim as the query string, replacing any query already present in URL; it is
+// meant for cases such as pre-signed URLs, where the exact encoding matters. It is an error to use it along with
+// Params or ParamsMulti.
+// If Decompress is true, response bodies encoded with gzip or deflate, as indicated by the Content-Encoding header,
+// are transparently decompressed by [Send]; more codings can be supported using [RegisterDecompressor].
+// Trailer holds headers sent after the request body. They are only sent when the body is transferred using chunked
+// encoding, i.e. with a [Request.BodyReader] of unknown length. Keys must be present before sending, but values may
+// be filled while the body is being read, e.g. for checksums; the map is used as is for that reason.
+// MaxBodySize, if positive, is the maximum size of the response body read by [Send], counted after decompression.
+// Larger bodies result in [ErrBodyTooLarge].
+// If DisableRedirects is true, redirects are not followed regardless of the client's CheckRedirect policy and the 3xx
+// response, including its Location header, is returned as is.
 type Request struct {
-	Method  Method
-	URL     string
-	Header  http.Header
-	Cookies []*http.Cookie
-	Body    []byte
-	Params  map[string]string
+	Method           Method
+	URL              string
+	Header           http.Header
+	Cookies          []*http.Cookie
+	Body             []byte
+	BodyReader       io.Reader
+	ContentLength    int64
+	Params           map[string]string
+	ParamsMulti      url.Values
+	RawQuery         string
+	Decompress       bool
+	Trailer          http.Header
+	MaxBodySize      int64
+	DisableRedirects bool
 }
 
-// Response consists of some of the HTTP response data.
+// Response consists of some of the HTTP response data. FinalURL is the URL of the request which produced the
+// response; if redirects were followed, it differs from the original [Request.URL]. Duration is the time it took from
+// sending the request until the whole response body was read. Trailer holds the HTTP trailers sent after the body; it
+// is only present when the server used chunked encoding and announced them with the Trailer header. Timings is only
+// set when [WithTimings] is used.
 type Response struct {
 	Body       []byte
 	Header     http.Header
+	Trailer    http.Header
 	Cookies    []*http.Cookie
 	StatusCode int
+	FinalURL   string
+	Duration   time.Duration
+	Timings    *Timings
+
+	proto string
+	// request is the request which produced the response, used by [Response.Follow].
+	request Request
+}
+
+// Clone returns a deep copy of the request. Since Header, Cookies, Body and the parameters are reference types, a plain
+// copy of a [Request] shares them with the original; Clone is the safe way of deriving requests from a template.
+// BodyReader can't be copied, and is shared between the two.
+func (r Request) Clone() Request {
+	c := r
+	c.Header = r.Header.Clone()
+	c.Trailer = r.Trailer.Clone()
+	c.Body = bytes.Clone(r.Body)
+	c.Params = maps.Clone(r.Params)
+	if r.ParamsMulti != nil {
+		c.ParamsMulti = make(url.Values, len(r.ParamsMulti))
+		for k, v := range r.ParamsMulti {
+			c.ParamsMulti[k] = slices.Clone(v)
+		}
+	}
+	if r.Cookies != nil {
+		c.Cookies = make([]*http.Cookie, len(r.Cookies))
+		for i, cookie := range r.Cookies {
+			if cookie != nil {
+				cc := *cookie
+				c.Cookies[i] = &cc
+			}
+		}
+	}
+	return c
+}
+
+// SetHeader sets the header key to value, replacing any existing values associated with it. Header is initialized
+// if it is nil.
+func (r *Request) SetHeader(key, value string) {
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set(key, value)
+}
+
+// AddHeader adds value to the header key, appending to any existing values associated with it. Header is initialized
+// if it is nil.
+func (r *Request) AddHeader(key, value string) {
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Add(key, value)
 }
 
 // Send sends an HTTP request based on the [Request]. It uses the provided [http.Client] in order to reuse the client.
-// It returns [Response] if successful, or an error otherwise.
-func Send(ctx context.Context, client *http.Client, r Request) (*Response, error) {
-	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, bytes.NewBuffer(r.Body))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+// It returns [Response] if successful, or an error otherwise. If ctx is already done, the request is not sent and the
+// returned error wraps the context's error. Whether the deadline of ctx or the Timeout of the client is exceeded,
+// while sending the request or reading the response, the returned error matches [context.DeadlineExceeded]; see also
+// [IsTimeout].
+//
+// If reading the response body fails partway, e.g. because the connection dropped, the error is returned along with a
+// non-nil [Response], holding the status code, the headers and whatever part of the body was read; it is not
+// decompressed. This is useful for resuming downloads, but callers must not assume a nil Response on errors.
+//
+// Send is the buffered convenience wrapper around [SendRaw]; the whole response body is read and the connection is
+// released before returning. The body is closed on every path, including when ctx is cancelled or the client times
+// out midway through reading it, so that no connection or goroutine is leaked; the connection is then discarded
+// rather than reused.
+func Send(ctx context.Context, client *http.Client, r Request, opts ...Option) (*Response, error) {
+	o := newOptions(opts)
+	for _, h := range o.hooks {
+		if h.BeforeSend != nil {
+			h.BeforeSend(&r)
+		}
 	}
-	req.Header = r.Header
-	for _, c := range r.Cookies {
-		req.AddCookie(c)
+
+	start := time.Now()
+	resp, err := send(ctx, client, r, o)
+
+	var status int
+	if resp != nil {
+		status = resp.StatusCode
 	}
+	o.metrics.ObserveRequest(r.Method, hostOf(r.URL), status, time.Since(start), err)
 
-	q := req.URL.Query()
-	for k, v := range r.Params {
-		q.Add(k, v)
+	for _, h := range o.hooks {
+		if h.AfterSend != nil {
+			h.AfterSend(&r, resp, err)
+		}
 	}
-	req.URL.RawQuery = q.Encode()
+	return resp, err
+}
 
-	resp, err := client.Do(req)
+// send does the actual work of [Send].
+func send(ctx context.Context, client *http.Client, r Request, o *options) (*Response, error) {
+	start := time.Now()
+	resp, err := sendRaw(ctx, client, r, o)
 	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
+		return nil, err
 	}
 
 	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	body, err := readAll(resp.Body, r.MaxBodySize)
+	if errors.Is(err, ErrBodyTooLarge) {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
+	duration := time.Since(start)
+	if err != nil {
+		return newResponse(r, resp, body, duration, o), fmt.Errorf("reading response: %w: %w", ErrTransport, err)
+	}
+	if r.Decompress {
+		var ok bool
+		body, ok, err = decompress(resp.Header, body, r.MaxBodySize)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resp.Header.Del("Content-Encoding")
+			resp.Header.Del("Content-Length")
+		}
+	}
+
+	return newResponse(r, resp, body, duration, o), nil
+}
+
+// newResponse creates the [Response] of r from resp and its body.
+func newResponse(r Request, resp *http.Response, body []byte, duration time.Duration, o *options) *Response {
+	finalURL := r.URL
+	if resp.Request != nil {
+		finalURL = resp.Request.URL.String()
+	}
 
 	response := &Response{
 		Body:       body,
 		StatusCode: resp.StatusCode,
 		Header:     resp.Header,
+		Trailer:    resp.Trailer,
 		Cookies:    resp.Cookies(),
+		FinalURL:   finalURL,
+		Duration:   duration,
+		proto:      resp.Proto,
+		request:    r,
+	}
+	if o.collector != nil {
+		response.Timings = o.collector.timings()
+	}
+	return response
+}
+
+// SendRaw sends an HTTP request based on the [Request], and returns the [http.Response] as is. The response body is
+// neither read nor closed, and doing so is the caller's responsibility; the connection is only reused once the body is
+// read to the end and closed, which [DrainAndClose] takes care of.
+//
+// It is meant for advanced use cases which need data not exposed by [Response], such as TLS or protocol information.
+func SendRaw(ctx context.Context, client *http.Client, r Request, opts ...Option) (*http.Response, error) {
+	return sendRaw(ctx, client, r, newOptions(opts))
+}
+
+// sendRaw does the actual work of [SendRaw].
+func sendRaw(ctx context.Context, client *http.Client, r Request, o *options) (*http.Response, error) {
+	// Fail fast on a done context, instead of depending on when the transport gets to check it.
+	if err := ctx.Err(); err != nil {
+		closeBodyReader(r)
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	// An empty method means GET, as it does for http.NewRequest.
+	method := cmp.Or(r.Method, GET)
+	if o.validateMethod && !IsValidMethod(method) && !slices.Contains(o.customMethods, method) {
+		closeBodyReader(r)
+		return nil, fmt.Errorf("unknown method: %q", r.Method)
+	}
+
+	if o.timings {
+		o.collector = new(timingsCollector)
+		ctx = o.collector.trace(ctx)
+	}
+
+	req, err := newRequest(ctx, r)
+	if err != nil {
+		closeBodyReader(r)
+		return nil, err
+	}
+	if o.expectContinue && req.Body != http.NoBody {
+		req.Header.Set("Expect", "100-continue")
+	}
+	for _, h := range o.hooks {
+		if h.OnRequest != nil {
+			h.OnRequest(req)
+		}
+	}
+	if o.signer != nil {
+		err = o.signer.Sign(req)
+		if err != nil {
+			closeBodyReader(r)
+			return nil, fmt.Errorf("signing request: %w", err)
+		}
+	}
+
+	if r.DisableRedirects {
+		c := *client
+		c.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+		client = &c
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w: %w", ErrTransport, err)
+	}
+	return resp, nil
+}
+
+// closeBodyReader closes the [Request.BodyReader] of a request that fails before reaching the [http.Client], which
+// would otherwise have closed it, so that its writer, e.g. the one of [MultipartBody.StreamRequest], isn't left
+// blocked forever.
+func closeBodyReader(r Request) {
+	if c, ok := r.BodyReader.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// SendTimeout is like [Send], but limits the request to the given timeout without the need of a separate
+// [http.Client]. If the timeout is reached, the returned error wraps [context.DeadlineExceeded].
+func SendTimeout(
+	ctx context.Context, client *http.Client, r Request, timeout time.Duration, opts ...Option,
+) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := Send(ctx, client, r, opts...)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	}
+	return resp, err
+}
+
+// ClientFunc selects the [http.Client] for sending a request, e.g. based on values stored in ctx.
+type ClientFunc func(ctx context.Context, r Request) *http.Client
+
+// SendFunc is like [Send], but obtains the client from clientFunc. It is an error for clientFunc to return nil.
+func SendFunc(ctx context.Context, clientFunc ClientFunc, r Request, opts ...Option) (*Response, error) {
+	client := clientFunc(ctx, r)
+	if client == nil {
+		return nil, errors.New("selecting client: no client was returned")
 	}
-	return response, nil
+	return Send(ctx, client, r, opts...)
+}
+
+// SendRT is like [Send], but sends the request through the round tripper rt, wrapped in a minimal [http.Client]. It
+// is convenient for chains of middleware implemented as round trippers, and for mocks in tests. The client has no
+// timeout, and follows redirects with the default policy; if rt is nil, [http.DefaultTransport] is used.
+func SendRT(ctx context.Context, rt http.RoundTripper, r Request, opts ...Option) (*Response, error) {
+	return Send(ctx, &http.Client{Transport: rt}, r, opts...)
 }
 
 // SendParse is intended for use cases which caller is sure about the response structure. Optionally, caller can provide
-// a number of acceptable status codes. Function will return an error if the response's status code is not in them.
+// a number of acceptable status codes. Function will return a [*StatusError] if the response's status code is not in
+// them.
 //
 // This function requires the caller to specify the response type. Return value will be a pointer of that type, or an
-// error if something goes wrong.
+// error if something goes wrong. The response body is decoded as JSON; use [SendParseWith] for other formats. Unless
+// the request has an Accept header, it is set to application/json.
 func SendParse[T any](ctx context.Context, client *http.Client, r Request, acceptable ...int) (*T, error) {
+	return SendParseWith[T](ctx, client, r, JSONDecoder{}, acceptable...)
+}
+
+// SendParseStrict is like [SendParse], but fails with a [*DecodeError] if the response has fields not defined by T.
+// It uses [StrictJSONDecoder].
+func SendParseStrict[T any](ctx context.Context, client *http.Client, r Request, acceptable ...int) (*T, error) {
+	return SendParseWith[T](ctx, client, r, StrictJSONDecoder{}, acceptable...)
+}
+
+// SendParseWith is like [SendParse], but decodes the response body using the provided [Decoder]. If decoding fails, a
+// [*DecodeError] is returned.
+func SendParseWith[T any](ctx context.Context, client *http.Client, r Request, dec Decoder, acceptable ...int) (*T, error) {
+	var accept func(int) bool
+	if len(acceptable) != 0 {
+		accept = func(code int) bool { return slices.Contains(acceptable, code) }
+	}
+	return sendParse[T](ctx, client, r, dec, accept)
+}
+
+// SendParseFunc is like [SendParse], but the response's status code is acceptable if accept returns true for it. A
+// nil accept accepts all status codes. [AcceptRange] can be used for accepting a range, such as any 2xx.
+func SendParseFunc[T any](ctx context.Context, client *http.Client, r Request, accept func(int) bool) (*T, error) {
+	return sendParse[T](ctx, client, r, JSONDecoder{}, accept)
+}
+
+// AcceptRange returns a function accepting status codes between low and high, inclusive, for use with
+// [SendParseFunc].
+func AcceptRange(low, high int) func(int) bool {
+	return func(code int) bool {
+		return code >= low && code <= high
+	}
+}
+
+// sendParse does the actual work of the SendParse family of functions.
+func sendParse[T any](ctx context.Context, client *http.Client, r Request, dec Decoder, accept func(int) bool) (*T, error) {
+	if m, ok := dec.(interface{ MediaType() string }); ok && r.Header.Get("Accept") == "" {
+		r.Header = r.Header.Clone()
+		r.SetHeader("Accept", m.MediaType())
+	}
+
 	resp, err := Send(ctx, client, r)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(acceptable) != 0 && !slices.Contains(acceptable, resp.StatusCode) {
-		return nil, fmt.Errorf("unacceptable status code: %d", resp.StatusCode)
+	if accept != nil && !accept(resp.StatusCode) {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: resp.Body, Header: resp.Header}
 	}
 
 	t := new(T)
-	err = json.Unmarshal(resp.Body, t)
+	err = dec.Decode(resp.Body, t)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshaling response: %w", err)
+		return nil, &DecodeError{StatusCode: resp.StatusCode, Body: resp.Body, Header: resp.Header, Err: err}
 	}
 
 	return t, nil
 }
+
+// newRequest builds an [http.Request] out of the [Request], including its headers, cookies and query parameters.
+func newRequest(ctx context.Context, r Request) (*http.Request, error) {
+	// Requests without a body must be truly bodyless, otherwise some servers see an empty one.
+	var body io.Reader = http.NoBody
+	switch {
+	case r.BodyReader != nil:
+		body = r.BodyReader
+	case len(r.Body) != 0:
+		body = bytes.NewReader(r.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	// Copy the header instead of assigning it, so that later changes to the request, such as adding cookies, don't
+	// modify the caller's map.
+	for k, v := range HeadersFromContext(ctx) {
+		req.Header[k] = slices.Clone(v)
+	}
+	for k, v := range r.Header {
+		delete(req.Header, http.CanonicalHeaderKey(k))
+		req.Header[k] = slices.Clone(v)
+	}
+	if DefaultUserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+	for _, c := range r.Cookies {
+		req.AddCookie(c)
+	}
+
+	// Host and Content-Length are ignored by the transport when present in the header, and must be set on the
+	// request's fields instead.
+	if host := r.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	if cl := r.Header.Get("Content-Length"); cl != "" {
+		n, err := strconv.ParseInt(cl, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid Content-Length header: %q", cl)
+		}
+		req.ContentLength = n
+	}
+	if r.BodyReader != nil && r.ContentLength > 0 {
+		req.ContentLength = r.ContentLength
+	}
+
+	req.Trailer = r.Trailer
+
+	if r.RawQuery != "" {
+		if len(r.Params) != 0 || len(r.ParamsMulti) != 0 {
+			return nil, errors.New("creating request: RawQuery can't be used along with Params or ParamsMulti")
+		}
+		req.URL.RawQuery = r.RawQuery
+		return req, nil
+	}
+	// Re-encoding sorts and normalizes the query already present in the URL, which would break signed URLs.
+	if len(r.Params) == 0 && len(r.ParamsMulti) == 0 {
+		return req, nil
+	}
+
+	q := req.URL.Query()
+	for k, v := range r.Params {
+		q.Add(k, v)
+	}
+	for k, vs := range r.ParamsMulti {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req, nil
+}
+
+// readAll reads r until EOF. If limit is positive and r has more data than it, [ErrBodyTooLarge] is returned. On other
+// errors, the data read so far is returned along with them.
+func readAll(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return b, err
+	}
+	if int64(len(b)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	return b, nil
+}
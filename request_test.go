@@ -0,0 +1,673 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendDoesNotMutateHeader(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Clone()
+	}))
+	defer srv.Close()
+
+	header := http.Header{"X-Custom": {"value"}, "x-trace": {"request"}}
+	want := header.Clone()
+	r := Request{
+		URL:     srv.URL,
+		Header:  header,
+		Cookies: []*http.Cookie{{Name: "session", Value: "abc"}},
+	}
+	ctx := WithHeaders(context.Background(), http.Header{"X-Trace": {"context"}})
+	hooks := Hooks{OnRequest: func(req *http.Request) { req.Header.Set("X-Custom", "mutated") }}
+
+	_, err := Send(ctx, srv.Client(), r, WithHooks(hooks))
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if !reflect.DeepEqual(header, want) {
+		t.Errorf("caller's header = %v, want %v", header, want)
+	}
+	if v := got.Values("Cookie"); len(v) != 1 || v[0] != "session=abc" {
+		t.Errorf("server got Cookie %q, want %q", v, "session=abc")
+	}
+	if v := got.Values("X-Trace"); len(v) != 1 || v[0] != "request" {
+		t.Errorf("server got X-Trace %q, want the request's value only", v)
+	}
+	if v := got.Get("X-Custom"); v != "mutated" {
+		t.Errorf("server got X-Custom %q, want %q", v, "mutated")
+	}
+}
+
+func TestSendWithoutBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{name: "nil", body: nil},
+		{name: "empty", body: []byte{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				contentLength int64
+				header        http.Header
+				body          []byte
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				contentLength, header = req.ContentLength, req.Header.Clone()
+				body, _ = io.ReadAll(req.Body)
+			}))
+			defer srv.Close()
+
+			_, err := Send(context.Background(), srv.Client(), Request{Method: GET, URL: srv.URL, Body: tt.body})
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+
+			if contentLength != 0 {
+				t.Errorf("ContentLength = %d, want 0", contentLength)
+			}
+			if v, ok := header["Content-Length"]; ok {
+				t.Errorf("Content-Length header = %q, want none", v)
+			}
+			if len(body) != 0 {
+				t.Errorf("body = %q, want empty", body)
+			}
+		})
+	}
+}
+
+func TestSendCancelMidReadDoesNotLeak(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		states = make(map[net.Conn]http.ConnState)
+	)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-req.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	srv.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		states[c] = s
+	}
+	srv.Start()
+	defer srv.Close()
+
+	transport := &http.Transport{}
+	client := &http.Client{Transport: transport}
+	goroutines := runtime.NumGoroutine()
+
+	const requests = 10
+	for range requests {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		resp, err := Send(ctx, client, Request{URL: srv.URL})
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Send error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if resp == nil || string(resp.Body) != "partial" {
+			t.Fatalf("Send response = %+v, want the partial body", resp)
+		}
+	}
+	transport.CloseIdleConnections()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		open := 0
+		for _, s := range states {
+			if s != http.StateClosed {
+				open++
+			}
+		}
+		total := len(states)
+		mu.Unlock()
+
+		leaked := runtime.NumGoroutine() - goroutines
+		if open == 0 && leaked <= 0 {
+			if total != requests {
+				t.Errorf("server saw %d connections, want %d", total, requests)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections still open and %d goroutines leaked", open, leaked)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSendStreamedContentLength(t *testing.T) {
+	var (
+		transferEncoding []string
+		contentLength    int64
+		body             []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		transferEncoding, contentLength = req.TransferEncoding, req.ContentLength
+		body, _ = io.ReadAll(req.Body)
+	}))
+	defer srv.Close()
+
+	// MultiReader hides the strings.Reader, whose length would otherwise be detected by net/http.
+	content := strings.Repeat("x", 5000)
+	r := Request{
+		Method:        PUT,
+		URL:           srv.URL,
+		BodyReader:    io.MultiReader(strings.NewReader(content)),
+		ContentLength: int64(len(content)),
+	}
+	_, err := Send(context.Background(), srv.Client(), r)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if slices.Contains(transferEncoding, "chunked") {
+		t.Errorf("Transfer-Encoding = %q, want no chunked encoding", transferEncoding)
+	}
+	if contentLength != int64(len(content)) {
+		t.Errorf("ContentLength = %d, want %d", contentLength, len(content))
+	}
+	if string(body) != content {
+		t.Errorf("server read %d bytes, want %d", len(body), len(content))
+	}
+
+	r.BodyReader = io.MultiReader(strings.NewReader(content))
+	r.ContentLength = int64(len(content)) + 1
+	_, err = Send(context.Background(), srv.Client(), r)
+	if !errors.Is(err, ErrTransport) {
+		t.Errorf("Send with a short reader: error = %v, want %v", err, ErrTransport)
+	}
+}
+
+func TestSendPreservesSignedQuery(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.URL.RawQuery
+	}))
+	defer srv.Close()
+
+	const signed = "X-Amz-Signature=a%2Fb&A=1&X-Amz-Credential=AKIA%2F20260101%2Fus-east-1&empty"
+	_, err := Send(context.Background(), srv.Client(), Request{URL: srv.URL + "/object?" + signed})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got != signed {
+		t.Errorf("RawQuery = %q, want %q", got, signed)
+	}
+
+	r := Request{URL: srv.URL + "/object?X-Amz-Signature=a%2Fb&A=1", Params: map[string]string{"b": "2"}}
+	_, err = Send(context.Background(), srv.Client(), r)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if want := "A=1&X-Amz-Signature=a%2Fb&b=2"; got != want {
+		t.Errorf("RawQuery with Params = %q, want %q", got, want)
+	}
+}
+
+func TestSendStreamRequestFailingEarlyDoesNotLeak(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	const requests = 10
+	for range requests {
+		r := NewMultipart().FileBytes("file", "a.txt", []byte("content")).StreamRequest(POST, "http://127.0.0.1:0")
+		_, err := Send(ctx, http.DefaultClient, r)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Send error = %v, want %v", err, context.Canceled)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > goroutines {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines leaked", runtime.NumGoroutine()-goroutines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBreakerStateMachine(t *testing.T) {
+	// Events: success, failure and cancel record the outcome of a request allowed while closed, or in flight since
+	// then; trial-* ones take the half-open trial first. cooldown makes the cooldown of an open breaker elapse.
+	tests := []struct {
+		name     string
+		events   []string
+		want     BreakerState
+		failures int
+	}{
+		{name: "below threshold", events: []string{"failure", "failure"}, want: BreakerClosed, failures: 2},
+		{name: "success resets", events: []string{"failure", "failure", "success"}, want: BreakerClosed},
+		{name: "cancel is not counted", events: []string{"failure", "cancel", "cancel"}, want: BreakerClosed, failures: 1},
+		{name: "opens at threshold", events: []string{"failure", "failure", "failure"}, want: BreakerOpen, failures: 3},
+		{
+			name:   "half-opens after cooldown",
+			events: []string{"failure", "failure", "failure", "cooldown"}, want: BreakerHalfOpen, failures: 3,
+		},
+		{
+			name:   "late success doesn't close an open breaker",
+			events: []string{"failure", "failure", "failure", "success"}, want: BreakerOpen, failures: 3,
+		},
+		{
+			name:   "late success doesn't close a half-open breaker",
+			events: []string{"failure", "failure", "failure", "cooldown", "success"}, want: BreakerHalfOpen, failures: 3,
+		},
+		{
+			name:   "late failure doesn't reopen a half-open breaker",
+			events: []string{"failure", "failure", "failure", "cooldown", "failure"}, want: BreakerHalfOpen, failures: 3,
+		},
+		{
+			name:   "successful trial closes",
+			events: []string{"failure", "failure", "failure", "cooldown", "trial-success"}, want: BreakerClosed,
+		},
+		{
+			name:   "failed trial reopens",
+			events: []string{"failure", "failure", "failure", "cooldown", "trial-failure"}, want: BreakerOpen, failures: 4,
+		},
+		{
+			name:   "cancelled trial lets another through",
+			events: []string{"failure", "failure", "failure", "cooldown", "trial-cancel", "trial-success"},
+			want:   BreakerClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker(3, time.Hour)
+			for _, e := range tt.events {
+				if e == "cooldown" {
+					b.mu.Lock()
+					b.openedAt = b.openedAt.Add(-time.Hour)
+					b.mu.Unlock()
+					continue
+				}
+
+				trial := strings.HasPrefix(e, "trial-")
+				if trial {
+					gotTrial, ok := b.allow()
+					if !gotTrial || !ok {
+						t.Fatalf("%s: allow() = %t, %t, want the trial", e, gotTrial, ok)
+					}
+					if gotTrial, ok = b.allow(); ok {
+						t.Fatalf("%s: allow() during the trial = %t, %t, want a rejection", e, gotTrial, ok)
+					}
+				}
+				outcome := strings.TrimPrefix(e, "trial-")
+				b.record(trial, outcome == "failure", outcome != "cancel")
+			}
+
+			if got := b.State(); got != tt.want {
+				t.Errorf("State() = %v, want %v", got, tt.want)
+			}
+			if got := b.Failures(); got != tt.failures {
+				t.Errorf("Failures() = %d, want %d", got, tt.failures)
+			}
+			if _, ok := b.allow(); ok != (tt.want != BreakerOpen) {
+				t.Errorf("allow() = %t in state %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendBreakerRejectsWhileOpen(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	b := NewBreaker(2, time.Hour)
+	for range 2 {
+		_, err := SendBreaker(context.Background(), srv.Client(), Request{URL: srv.URL}, b)
+		if err != nil {
+			t.Fatalf("SendBreaker: %v", err)
+		}
+	}
+	_, err := SendBreaker(context.Background(), srv.Client(), Request{URL: srv.URL}, b)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("SendBreaker error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if hits != 2 {
+		t.Errorf("server got %d requests, want 2", hits)
+	}
+}
+
+func TestSendValidateMethod(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	tests := []struct {
+		method  Method
+		wantErr bool
+	}{
+		{method: "", wantErr: false},
+		{method: GET, wantErr: false},
+		{method: "PROPFIND", wantErr: false},
+		{method: "GTE", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := Send(context.Background(), srv.Client(), Request{Method: tt.method, URL: srv.URL}, ValidateMethod("PROPFIND"))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Send with method %q: error = %v, want error %t", tt.method, err, tt.wantErr)
+		}
+	}
+}
+
+func TestToCurlMethod(t *testing.T) {
+	tests := []struct {
+		method Method
+		want   string
+	}{
+		{method: "", want: "curl 'https://example.com'"},
+		{method: GET, want: "curl 'https://example.com'"},
+		{method: HEAD, want: "curl -I 'https://example.com'"},
+		{method: DELETE, want: "curl -X 'DELETE' 'https://example.com'"},
+	}
+	for _, tt := range tests {
+		if got := ToCurl(Request{Method: tt.method, URL: "https://example.com"}); got != tt.want {
+			t.Errorf("ToCurl with method %q = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestDigestAuthorizeRFC7616(t *testing.T) {
+	// The example of RFC 7616, section 3.9.1.
+	const (
+		challenge = `realm="http-auth@example.org", qop="auth, auth-int", ` +
+			`nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`
+		cnonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+	)
+	tests := []struct {
+		algorithm string
+		response  string
+	}{
+		{algorithm: "MD5", response: "8ca523f5e9506fed4657c9700eebdbec"},
+		{algorithm: "SHA-256", response: "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			h := http.Header{"Www-Authenticate": {"Digest " + challenge + ", algorithm=" + tt.algorithm}}
+			c, ok := parseDigestChallenge(h)
+			if !ok {
+				t.Fatalf("parseDigestChallenge(%q) found no challenge", h)
+			}
+			auth, err := c.authorize("GET", "/dir/index.html", nil, "Mufasa", "Circle of Life", cnonce)
+			if err != nil {
+				t.Fatalf("authorize: %v", err)
+			}
+
+			scheme, rest, _ := strings.Cut(auth, " ")
+			params := parseAuthParams(rest)
+			want := map[string]string{
+				"username":  "Mufasa",
+				"realm":     "http-auth@example.org",
+				"uri":       "/dir/index.html",
+				"algorithm": tt.algorithm,
+				"response":  tt.response,
+				"opaque":    "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS",
+				"qop":       "auth",
+				"nc":        "00000001",
+				"cnonce":    cnonce,
+			}
+			if scheme != "Digest" {
+				t.Errorf("scheme = %q, want Digest", scheme)
+			}
+			for k, v := range want {
+				if params[k] != v {
+					t.Errorf("%s = %q, want %q", k, params[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestSendDigest(t *testing.T) {
+	const challenge = `Digest realm="test", qop="auth", nonce="abc", algorithm=SHA-256`
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hits++
+		_, _ = io.Copy(io.Discard, req.Body)
+		scheme, rest, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+		if scheme != "Digest" {
+			w.Header().Set("WWW-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		params := parseAuthParams(rest)
+		c, _ := parseDigestChallenge(http.Header{"Www-Authenticate": {challenge}})
+		want, _ := c.authorize(req.Method, req.URL.RequestURI(), nil, "user", "pass", params["cnonce"])
+		if want != req.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := SendDigest(context.Background(), srv.Client(), Request{URL: srv.URL + "/a?b=c"}, "user", "pass")
+	if err != nil {
+		t.Fatalf("SendDigest: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || hits != 2 {
+		t.Errorf("SendDigest: status %d after %d requests, want 200 after 2", resp.StatusCode, hits)
+	}
+
+	hits = 0
+	r := Request{Method: POST, URL: srv.URL, BodyReader: strings.NewReader("body")}
+	resp, err = SendDigest(context.Background(), srv.Client(), r, "user", "pass")
+	if err != nil {
+		t.Fatalf("SendDigest with a BodyReader: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized || hits != 1 {
+		t.Errorf("SendDigest with a BodyReader: status %d after %d requests, want 401 after 1", resp.StatusCode, hits)
+	}
+}
+
+func TestSendSSEFraming(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []Event
+	}{
+		{
+			name:   "single event",
+			stream: "data: hello\n\n",
+			want:   []Event{{Data: "hello"}},
+		},
+		{
+			name:   "CRLF line endings",
+			stream: "event: greeting\r\ndata: hello\r\n\r\n",
+			want:   []Event{{Event: "greeting", Data: "hello"}},
+		},
+		{
+			name:   "multi-line data",
+			stream: "data: first\ndata:second\ndata\n\n",
+			want:   []Event{{Data: "first\nsecond\n"}},
+		},
+		{
+			name:   "comments and unknown fields",
+			stream: ": keep-alive\nfoo: bar\ndata: hello\n\n",
+			want:   []Event{{Data: "hello"}},
+		},
+		{
+			name:   "id carries over",
+			stream: "id: 1\ndata: a\n\ndata: b\n\nid: 2\ndata: c\n\nid\ndata: d\n\n",
+			want:   []Event{{ID: "1", Data: "a"}, {ID: "1", Data: "b"}, {ID: "2", Data: "c"}, {Data: "d"}},
+		},
+		{
+			name:   "retry",
+			stream: "retry: 1500\ndata: a\n\nretry: soon\ndata: b\n\n",
+			want:   []Event{{Data: "a", Retry: 1500 * time.Millisecond}, {Data: "b"}},
+		},
+		{
+			name:   "event without data is discarded",
+			stream: "event: ping\n\ndata: hello\n\n",
+			want:   []Event{{Data: "hello"}},
+		},
+		{
+			name:   "incomplete event is discarded",
+			stream: "data: hello\n\ndata: cut",
+			want:   []Event{{Data: "hello"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, tt.stream)
+			}))
+			defer srv.Close()
+
+			s, err := SendSSE(context.Background(), srv.Client(), Request{URL: srv.URL})
+			if err != nil {
+				t.Fatalf("SendSSE: %v", err)
+			}
+			defer s.Close()
+
+			var got []Event
+			for {
+				ev, err := s.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next: %v", err)
+				}
+				got = append(got, ev)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendSSEStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, err := SendSSE(context.Background(), srv.Client(), Request{URL: srv.URL})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("SendSSE error = %v, want a %d status error", err, http.StatusServiceUnavailable)
+	}
+}
+
+func TestSendCached(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		etag         bool
+		changed      bool
+		method       Method
+		wantHits     int
+		wantBody     string
+	}{
+		{name: "fresh", cacheControl: "max-age=60", wantHits: 1, wantBody: "v1"},
+		{name: "fresh HEAD", cacheControl: "max-age=60", method: HEAD, wantHits: 1},
+		{name: "revalidated", cacheControl: "no-cache", etag: true, wantHits: 2, wantBody: "v1"},
+		{name: "changed", cacheControl: "max-age=0", etag: true, changed: true, wantHits: 2, wantBody: "v2"},
+		{name: "not revalidatable", cacheControl: "max-age=0", wantHits: 2, wantBody: "v2"},
+		{name: "no-store", cacheControl: "no-store, max-age=60", wantHits: 2, wantBody: "v2"},
+		{name: "POST", cacheControl: "max-age=60", method: POST, wantHits: 2, wantBody: "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				hits        int
+				ifNoneMatch string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				hits++
+				ifNoneMatch = req.Header.Get("If-None-Match")
+				version := 1
+				if tt.changed || !tt.etag {
+					version = hits
+				}
+
+				w.Header().Set("Cache-Control", tt.cacheControl)
+				if tt.etag {
+					etag := fmt.Sprintf(`"v%d"`, version)
+					if ifNoneMatch == etag {
+						w.Header().Set("X-Revalidated", "true")
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+					w.Header().Set("ETag", etag)
+				}
+				_, _ = fmt.Fprintf(w, "v%d", version)
+			}))
+			defer srv.Close()
+
+			cache := NewMemoryCache(10)
+			r := Request{Method: tt.method, URL: srv.URL}
+			first, err := SendCached(context.Background(), srv.Client(), r, cache)
+			if err != nil {
+				t.Fatalf("first SendCached: %v", err)
+			}
+			if len(first.Body) != 0 {
+				first.Body[0] = 'x' // The cached copy must not be affected.
+			}
+
+			resp, err := SendCached(context.Background(), srv.Client(), r, cache)
+			if err != nil {
+				t.Fatalf("second SendCached: %v", err)
+			}
+			if hits != tt.wantHits {
+				t.Errorf("server got %d requests, want %d", hits, tt.wantHits)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+			if string(resp.Body) != tt.wantBody {
+				t.Errorf("Body = %q, want %q", resp.Body, tt.wantBody)
+			}
+			if tt.etag && hits == 2 && ifNoneMatch != `"v1"` {
+				t.Errorf("If-None-Match = %q, want %q", ifNoneMatch, `"v1"`)
+			}
+			if revalidated := resp.Header.Get("X-Revalidated") == "true"; revalidated != (tt.name == "revalidated") {
+				t.Errorf("response revalidated = %t, want %t", revalidated, !revalidated)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", &CacheEntry{})
+	c.Set("b", &CacheEntry{})
+	c.Get("a")
+	c.Set("c", &CacheEntry{})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%q) found = %t, want %t", key, ok, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package requesttest provides utilities for testing code which sends requests using the request package, without
+// making real network calls.
+package requesttest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// RoundTripperFunc is an adapter allowing the use of ordinary functions as [http.RoundTripper].
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements [http.RoundTripper].
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// MockResponse is a canned response returned by the client of [MockClient].
+//
+// If Method is not empty, the response only matches requests with that method. If URL is not empty, the response only
+// matches requests whose full URL or path equals it. If Err is not nil, it is returned instead of a response.
+// StatusCode defaults to 200.
+type MockResponse struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Err        error
+}
+
+func (m MockResponse) matches(req *http.Request) bool {
+	if m.Method != "" && m.Method != req.Method {
+		return false
+	}
+	if m.URL != "" && m.URL != req.URL.String() && m.URL != req.URL.Path {
+		return false
+	}
+	return true
+}
+
+func (m MockResponse) response(req *http.Request) *http.Response {
+	status := m.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := m.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(m.Body)),
+		ContentLength: int64(len(m.Body)),
+		Request:       req,
+	}
+}
+
+// MockClient returns an [http.Client] answering requests with the given responses, without any network calls. Each
+// request is answered by the first unused response matching it, so responses without Method and URL are returned in
+// order. Every response is used at most once; if no response matches a request, an error is returned.
+//
+// The returned client is safe for concurrent use.
+func MockClient(responses ...MockResponse) *http.Client {
+	var (
+		mu   sync.Mutex
+		used = make([]bool, len(responses))
+	)
+
+	rt := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for i, m := range responses {
+			if used[i] || !m.matches(req) {
+				continue
+			}
+			used[i] = true
+			if m.Err != nil {
+				return nil, m.Err
+			}
+			return m.response(req), nil
+		}
+		return nil, fmt.Errorf("requesttest: no mock response for %s %s", req.Method, req.URL)
+	})
+	return &http.Client{Transport: rt}
+}
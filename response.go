@@ -0,0 +1,228 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// RetryAfter parses the Retry-After header of the response, which can be either an amount of seconds or an HTTP
+// date. The returned boolean is false if the header is missing or malformed. Dates in the past yield zero.
+func (r *Response) RetryAfter() (time.Duration, bool) {
+	v := strings.TrimSpace(r.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(t), 0), true
+}
+
+// IsSuccess reports whether the status code is 2xx.
+func (r *Response) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// IsRedirect reports whether the status code is 3xx.
+func (r *Response) IsRedirect() bool {
+	return r.StatusCode >= 300 && r.StatusCode < 400
+}
+
+// IsClientError reports whether the status code is 4xx.
+func (r *Response) IsClientError() bool {
+	return r.StatusCode >= 400 && r.StatusCode < 500
+}
+
+// IsServerError reports whether the status code is 5xx.
+func (r *Response) IsServerError() bool {
+	return r.StatusCode >= 500 && r.StatusCode < 600
+}
+
+// NotModified reports whether the status code is 304, meaning a conditional request's cached response is still valid.
+func (r *Response) NotModified() bool {
+	return r.StatusCode == http.StatusNotModified
+}
+
+// Proto returns the protocol the response was received over, such as HTTP/1.1 or HTTP/2.0.
+func (r *Response) Proto() string {
+	return r.proto
+}
+
+// Status returns the canonical text of the status code, as reported by [http.StatusText].
+func (r *Response) Status() string {
+	return http.StatusText(r.StatusCode)
+}
+
+// RawSetCookies returns the unparsed values of the response's Set-Cookie headers. Unlike [Response.Cookies], which
+// silently drops malformed cookies, it includes all of them, helping to diagnose why a cookie is not being set, e.g.
+// because of an invalid attribute. [http.ParseSetCookie] reports the reason a value is rejected.
+func (r *Response) RawSetCookies() []string {
+	return slices.Clone(r.Header.Values("Set-Cookie"))
+}
+
+// JSON decodes the response body into v. If decoding fails, a [*DecodeError] is returned.
+func (r *Response) JSON(v any) error {
+	err := Unmarshal(r.Body, v)
+	if err != nil {
+		return &DecodeError{StatusCode: r.StatusCode, Body: r.Body, Header: r.Header, Err: err}
+	}
+	return nil
+}
+
+// String returns the response body as a string, mostly useful for logging and debugging.
+func (r *Response) String() string {
+	return string(r.Body)
+}
+
+// ContentType parses the Content-Type header of the response, returning its media type in lower case along with its
+// parameters, such as charset.
+func (r *Response) ContentType() (mediaType string, params map[string]string, err error) {
+	mediaType, params, err = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing content type: %w", err)
+	}
+	return mediaType, params, nil
+}
+
+// DetectedContentType returns the Content-Type header of the response. If the server omitted it, the content type is
+// sniffed from the first 512 bytes of the body using [http.DetectContentType], which falls back to
+// application/octet-stream; since that algorithm doesn't recognize JSON, text bodies holding a valid JSON object or
+// array are reported as application/json.
+func (r *Response) DetectedContentType() string {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		return ct
+	}
+	ct := http.DetectContentType(r.Body)
+	if strings.HasPrefix(ct, "text/plain") {
+		trimmed := bytes.TrimSpace(r.Body)
+		if len(trimmed) != 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+			return "application/json"
+		}
+	}
+	return ct
+}
+
+// Text returns the response body as a UTF-8 string, transcoding it based on the charset parameter of the Content-Type
+// header. If no charset is specified, the body is treated as UTF-8, same as [Response.String].
+func (r *Response) Text() (string, error) {
+	_, params, err := r.ContentType()
+	charset := params["charset"]
+	if err != nil || charset == "" {
+		return r.String(), nil
+	}
+
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return "", fmt.Errorf("unsupported charset %q: %w", charset, err)
+	}
+	b, _, err := transform.Bytes(enc.NewDecoder(), r.Body)
+	if err != nil {
+		return "", fmt.Errorf("decoding %s body: %w", charset, err)
+	}
+	return string(b), nil
+}
+
+// Location returns the Location header of the response as an absolute URL, resolving relative references against
+// [Response.FinalURL] as described by RFC 3986. If the header is missing, [ErrNoLocation] is returned.
+func (r *Response) Location() (*url.URL, error) {
+	loc := r.Header.Get("Location")
+	if loc == "" {
+		return nil, ErrNoLocation
+	}
+
+	base, err := url.Parse(r.FinalURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing response URL: %w", err)
+	}
+	u, err := base.Parse(loc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing location: %w", err)
+	}
+	return u, nil
+}
+
+// Follow sends the request that the redirect response points to, giving step by step control over redirect chains,
+// e.g. along with [Request.DisableRedirects]. The request is sent to [Response.Location], returning [ErrNoLocation] if
+// the header is missing, and it is an error if the status code is not one of 301, 302, 303, 307 or 308.
+//
+// As with the redirects followed by [http.Client], the 307 and 308 status codes preserve the original method and body,
+// while the others switch to GET without a body, except for HEAD requests. Headers are carried over, and so are the
+// request's cookies, updated with those set by the response. When the host changes, credentials are not: the
+// Authorization, Proxy-Authorization and Cookie headers are removed, and no cookies are sent. The query parameters of
+// the original request are not carried over either, since the location already includes the intended query. A body
+// provided as [Request.BodyReader] has already been consumed and can't be sent again, so an error is returned for such
+// requests when they would need it.
+func (r *Response) Follow(ctx context.Context, client *http.Client, opts ...Option) (*Response, error) {
+	switch r.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, fmt.Errorf("following redirect: status code %d is not a redirect", r.StatusCode)
+	}
+	loc, err := r.Location()
+	if err != nil {
+		return nil, fmt.Errorf("following redirect: %w", err)
+	}
+
+	next := r.request.Clone()
+	next.URL = loc.String()
+	next.Params = nil
+	next.ParamsMulti = nil
+	next.RawQuery = ""
+
+	if r.StatusCode == http.StatusTemporaryRedirect || r.StatusCode == http.StatusPermanentRedirect {
+		if next.BodyReader != nil {
+			return nil, errors.New("following redirect: a streamed BodyReader can't be sent again")
+		}
+	} else {
+		if next.Method != HEAD {
+			next.Method = GET
+		}
+		next.Body = nil
+		next.BodyReader = nil
+		next.ContentLength = 0
+		next.Trailer = nil
+		next.Header.Del("Content-Type")
+		next.Header.Del("Content-Length")
+		next.Header.Del("Content-Encoding")
+	}
+
+	if prev, err := url.Parse(r.FinalURL); err != nil || prev.Host != loc.Host {
+		next.Header.Del("Authorization")
+		next.Header.Del("Proxy-Authorization")
+		next.Header.Del("Cookie")
+		next.Cookies = nil
+		return Send(ctx, client, next, opts...)
+	}
+
+	for _, c := range r.Cookies {
+		next.Cookies = slices.DeleteFunc(next.Cookies, func(old *http.Cookie) bool { return old.Name == c.Name })
+		if c.MaxAge >= 0 && (c.Expires.IsZero() || c.Expires.After(time.Now())) {
+			next.Cookies = append(next.Cookies, &http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+
+	return Send(ctx, client, next, opts...)
+}
@@ -0,0 +1,148 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryConfig controls how [SendRetry] retries a request.
+//
+// MaxAttempts is the total number of attempts, including the first one. Values less than one are treated as one.
+// BaseDelay is the delay before the second attempt, which is doubled for every following one. If MaxDelay is
+// positive, no delay will be longer than it.
+// RetryOn decides whether the outcome of an attempt should be retried. If it is nil, requests failing with an error, a
+// 429 or a 5xx status code are retried.
+//
+// By default, only idempotent requests are retried: those with the GET, HEAD, PUT, DELETE, OPTIONS or TRACE methods,
+// or with an Idempotency-Key header. Replaying other requests, such as POST or PATCH, may duplicate their side effects
+// on the server; they are sent only once unless RetryNonIdempotent is true. It is an opt-in so that the zero value of
+// RetryConfig is safe.
+//
+// PerAttemptTimeout, if positive, limits each attempt individually, so that a single slow attempt can't consume the
+// whole deadline of ctx and starve the following ones. If it is zero and ctx has a deadline, each attempt gets an
+// equal share of the remaining time among the remaining attempts, including the time spent waiting between them.
+// Either way, the deadline of ctx still applies to the whole call.
+//
+// If Jitter is true, full jitter is applied: each backoff delay is picked randomly between zero and the computed
+// exponential delay, so that many clients don't retry in lockstep. Rand is the source of randomness for it, mainly
+// useful for deterministic tests; it is not safe for concurrent use, so it must not be shared between concurrent calls.
+// If it is nil, the top-level functions of [math/rand] are used. Delays requested by Retry-After are not jittered.
+type RetryConfig struct {
+	MaxAttempts        int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	RetryOn            func(*Response, error) bool
+	RetryNonIdempotent bool
+	PerAttemptTimeout  time.Duration
+	Jitter             bool
+	Rand               *rand.Rand
+}
+
+// SendRetry sends the [Request] using [Send], retrying it based on the [RetryConfig] with an exponential backoff.
+// Every attempt sends the whole Body again, along with the same headers; an Idempotency-Key set by
+// [WithIdempotencyKey] is therefore shared by all attempts. If ctx is cancelled while waiting for the next attempt,
+// the context's error is returned.
+//
+// If a response has the 429 or 503 status code along with a Retry-After header, the indicated duration is waited
+// instead of the computed backoff.
+//
+// A request with [Request.BodyReader] is sent only once, since the reader can't be replayed; Body should be used for
+// requests that may be retried.
+//
+// After the last attempt, its response and error are returned as is, even if RetryOn would have retried them.
+func SendRetry(ctx context.Context, client *http.Client, r Request, cfg RetryConfig, opts ...Option) (*Response, error) {
+	retryOn := cfg.RetryOn
+	if retryOn == nil {
+		retryOn = defaultRetryOn
+	}
+	attempts := max(cfg.MaxAttempts, 1)
+	if !cfg.RetryNonIdempotent && !isIdempotent(r) {
+		attempts = 1
+	}
+	// A BodyReader is used up by the first attempt, and can't be replayed.
+	if r.BodyReader != nil {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := cfg.attempt(ctx, client, r, attempts-attempt+1, opts)
+		if attempt >= attempts || !retryOn(resp, err) {
+			return resp, err
+		}
+
+		delay := cfg.delay(attempt)
+		if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+			if d, ok := resp.RetryAfter(); ok {
+				delay = d
+			}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("waiting to retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once, limited by the per-attempt timeout. remaining is the number of attempts left,
+// including this one.
+func (cfg RetryConfig) attempt(
+	ctx context.Context, client *http.Client, r Request, remaining int, opts []Option,
+) (*Response, error) {
+	timeout := cfg.PerAttemptTimeout
+	if deadline, ok := ctx.Deadline(); ok && timeout <= 0 {
+		timeout = time.Until(deadline) / time.Duration(remaining)
+	}
+	if timeout <= 0 {
+		return Send(ctx, client, r, opts...)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return Send(ctx, client, r, opts...)
+}
+
+// delay returns the backoff duration after the given attempt, starting from one.
+func (cfg RetryConfig) delay(attempt int) time.Duration {
+	d := cfg.BaseDelay
+	for i := 1; i < attempt; i++ {
+		if cfg.MaxDelay > 0 && d >= cfg.MaxDelay || d > math.MaxInt64/2 {
+			break
+		}
+		d *= 2
+	}
+	if cfg.MaxDelay > 0 {
+		d = min(d, cfg.MaxDelay)
+	}
+	if cfg.Jitter && d > 0 {
+		if cfg.Rand != nil {
+			d = time.Duration(cfg.Rand.Int63n(int64(d) + 1))
+		} else {
+			d = time.Duration(rand.Int63n(int64(d) + 1))
+		}
+	}
+	return d
+}
+
+func defaultRetryOn(resp *Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// isIdempotent reports whether sending r multiple times has the same effect as sending it once.
+func isIdempotent(r Request) bool {
+	switch r.Method {
+	case "", GET, HEAD, PUT, DELETE, OPTIONS, TRACE:
+		return true
+	}
+	return r.Header.Get("Idempotency-Key") != ""
+}
@@ -0,0 +1,129 @@
+package request
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a single Server-Sent Event. ID is the last event ID seen on the stream, which carries over to the following
+// events. Retry is the reconnection time requested by the server, or zero if it didn't specify one.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// EventStream reads Server-Sent Events from a text/event-stream response, one at a time. It must be closed by the
+// caller once done with it.
+type EventStream struct {
+	Header     http.Header
+	StatusCode int
+
+	ctx    context.Context
+	body   io.ReadCloser
+	reader *bufio.Reader
+	lastID string
+}
+
+// SendSSE sends an HTTP request based on the [Request], and returns an [EventStream] for consuming the response as
+// Server-Sent Events. Unless the request has an Accept header, it is set to text/event-stream. If the response's
+// status code is not 2xx, a [*StatusError] is returned.
+//
+// Special payloads such as [DONE] have no meaning to this function, and are left to the caller.
+func SendSSE(ctx context.Context, client *http.Client, r Request, opts ...Option) (*EventStream, error) {
+	if r.Header.Get("Accept") == "" {
+		r.Header = r.Header.Clone()
+		r.SetHeader("Accept", "text/event-stream")
+	}
+
+	resp, err := SendRaw(ctx, client, r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
+	}
+
+	s := &EventStream{
+		Header:     resp.Header,
+		StatusCode: resp.StatusCode,
+		ctx:        ctx,
+		body:       resp.Body,
+		reader:     bufio.NewReader(resp.Body),
+	}
+	return s, nil
+}
+
+// Next blocks until the next event is received, and returns it. At the end of the stream, it returns [io.EOF]; any
+// incomplete event is discarded. Once ctx is cancelled, it returns the context's error.
+func (s *EventStream) Next() (Event, error) {
+	var (
+		ev   Event
+		data strings.Builder
+		seen bool
+	)
+	for {
+		if err := s.ctx.Err(); err != nil {
+			return Event{}, err
+		}
+
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return Event{}, io.EOF
+			}
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return Event{}, ctxErr
+			}
+			return Event{}, fmt.Errorf("reading event: %w", err)
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if !seen {
+				ev = Event{}
+				continue
+			}
+			ev.ID = s.lastID
+			ev.Data = strings.TrimSuffix(data.String(), "\n")
+			return ev, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			seen = true
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "event":
+			ev.Event = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				ev.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// Close closes the underlying response body.
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
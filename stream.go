@@ -0,0 +1,121 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"sync"
+)
+
+// StreamResponse is the streaming counterpart of [Response]. Instead of buffering the whole body into memory, Body
+// is the raw response body as returned by the [http.Client].
+//
+// The caller is responsible for closing Body once done with it. Failing to do so leaks the underlying connection.
+type StreamResponse struct {
+	Body       io.ReadCloser
+	Header     http.Header
+	Cookies    []*http.Cookie
+	StatusCode int
+}
+
+// SendStream sends an HTTP request based on the [Request], same as [Send], but does not read the response body.
+// It is intended for large downloads where holding the entire body in memory is not an option.
+//
+// The returned [StreamResponse.Body] must always be closed by the caller. Cancelling ctx aborts any in-progress read
+// from Body.
+func SendStream(ctx context.Context, client *http.Client, r Request, opts ...Option) (*StreamResponse, error) {
+	resp, err := SendRaw(ctx, client, r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StreamResponse{
+		Body:       resp.Body,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+	}
+	return response, nil
+}
+
+// maxDrain is the maximum amount of unread body data discarded in order to reuse the connection. Past it, closing the
+// connection is cheaper than reading the rest of the body.
+const maxDrain = 64 << 10
+
+// DrainAndClose discards up to 64 KiB of the unread body of resp, then closes it. An [http.Client] only reuses a
+// connection once its previous response body has been read to the end and closed; responses returned by [SendRaw]
+// should therefore be closed with it when the caller doesn't need the whole body, e.g. when only the status code is
+// checked. Larger leftovers are not read, and the connection is closed instead. Since draining waits for the data to
+// arrive, it must not be used for slow or long-lived streams, which should simply be closed.
+func DrainAndClose(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+	_ = resp.Body.Close()
+}
+
+// SendStreamJSON sends an HTTP request based on the [Request], and decodes the response body as a top-level JSON array
+// one element at a time, keeping memory usage flat regardless of the array's size. Failing to send the request, or a
+// response with a non-2xx status code, is reported as the iterator's only element; the latter as a [*StatusError].
+//
+// The returned iterator may only be ranged over once, and stops at the first error it yields. The body is closed once
+// iteration is over, either by reaching the end of the array, an error, or the caller stopping early. The returned
+// close function closes the body as well, and must be called if the iterator is not used; it is safe to call it more
+// than once.
+func SendStreamJSON[T any](ctx context.Context, client *http.Client, r Request, opts ...Option) (iter.Seq2[T, error], func() error) {
+	if r.Header.Get("Accept") == "" {
+		r.Header = r.Header.Clone()
+		r.SetHeader("Accept", "application/json")
+	}
+
+	var zero T
+	resp, err := SendStream(ctx, client, r, opts...)
+	if err != nil {
+		return func(yield func(T, error) bool) { yield(zero, err) }, func() error { return nil }
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		err = &StatusError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
+		return func(yield func(T, error) bool) { yield(zero, err) }, func() error { return nil }
+	}
+
+	closeBody := sync.OnceValue(resp.Body.Close)
+	seq := func(yield func(T, error) bool) {
+		defer closeBody()
+
+		dec := json.NewDecoder(resp.Body)
+		tok, err := dec.Token()
+		if err != nil {
+			yield(zero, fmt.Errorf("reading array start: %w", err))
+			return
+		}
+		if tok != json.Delim('[') {
+			yield(zero, fmt.Errorf("expected a JSON array, got %v", tok))
+			return
+		}
+
+		for dec.More() {
+			var t T
+			err = dec.Decode(&t)
+			if err != nil {
+				yield(zero, fmt.Errorf("decoding array element: %w", err))
+				return
+			}
+			if !yield(t, nil) {
+				return
+			}
+		}
+
+		_, err = dec.Token()
+		if err != nil && !errors.Is(err, io.EOF) {
+			yield(zero, fmt.Errorf("reading array end: %w", err))
+		}
+	}
+	return seq, closeBody
+}
@@ -0,0 +1,85 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings breaks down the latency of a request, as captured when [WithTimings] is used. DNSLookup, Connect and
+// TLSHandshake are zero if the connection was reused from the pool, as indicated by ConnectionReused.
+// TimeToFirstByte is measured from when the request started obtaining a connection. If redirects were followed, the
+// timings are those of the last request.
+type Timings struct {
+	DNSLookup        time.Duration
+	Connect          time.Duration
+	TLSHandshake     time.Duration
+	TimeToFirstByte  time.Duration
+	ConnectionReused bool
+}
+
+// WithTimings makes [Send] capture the [Timings] of the request into [Response.Timings]. Tracing has a small overhead,
+// so it is only done when requested.
+func WithTimings() Option {
+	return func(o *options) {
+		o.timings = true
+	}
+}
+
+// timingsCollector gathers [Timings] from [httptrace.ClientTrace] callbacks, some of which may be called
+// concurrently.
+type timingsCollector struct {
+	mu sync.Mutex
+	t  Timings
+
+	start, dnsStart, connectStart, tlsStart time.Time
+}
+
+// trace returns a copy of ctx reporting to the collector.
+func (c *timingsCollector) trace(ctx context.Context) context.Context {
+	lock := func(f func()) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		f()
+	}
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			lock(func() { c.t, c.start = Timings{}, time.Now() })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			lock(func() { c.t.ConnectionReused = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			lock(func() { c.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			lock(func() { c.t.DNSLookup = time.Since(c.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			lock(func() { c.connectStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			lock(func() { c.t.Connect = time.Since(c.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			lock(func() { c.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			lock(func() { c.t.TLSHandshake = time.Since(c.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			lock(func() { c.t.TimeToFirstByte = time.Since(c.start) })
+		},
+	})
+}
+
+// timings returns the captured timings.
+func (c *timingsCollector) timings() *Timings {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.t
+	return &t
+}
@@ -0,0 +1,187 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	defaultTimeout               = 30 * time.Second
+	defaultDialTimeout           = 10 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultExpectContinueTimeout = time.Second
+)
+
+// DefaultClient returns an [http.Client] with sensible timeouts, unlike [http.DefaultClient] which has none. timeout
+// is the limit for the whole exchange, including reading the response body; if it is not positive, 30 seconds is
+// used. Dialing and TLS handshakes are limited to 10 seconds each, and requests sent with [WithExpectContinue] wait
+// up to a second for the server's interim response.
+func DefaultClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{
+		Transport: newTransport(),
+		Timeout:   timeout,
+	}
+}
+
+// ClientWithProxy is like [DefaultClient], but sends all requests through the proxy at proxyURL. Supported schemes are
+// http, https, socks5 and socks5h, all of which are natively handled by [http.Transport]; credentials can be provided
+// as the URL's user info.
+func ClientWithProxy(proxyURL string, timeout time.Duration) (*http.Client, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %q", u.Scheme)
+	}
+
+	client := DefaultClient(timeout)
+	client.Transport.(*http.Transport).Proxy = http.ProxyURL(u)
+	return client, nil
+}
+
+// TLSOptions configures the client created by [ClientTLSConfig].
+//
+// RootCAs and RootCAFile hold PEM encoded CA certificates, trusted in addition to the system's pool. The client
+// certificate for mutual TLS can be provided either as PEM encoded CertPEM and KeyPEM, or be loaded from CertFile and
+// KeyFile. Timeout is passed to [DefaultClient].
+//
+// InsecureSkipVerify disables verification of the server's certificate chain and host name. This makes the connection
+// vulnerable to man-in-the-middle attacks, and must only be used for testing.
+type TLSOptions struct {
+	RootCAs            []byte
+	RootCAFile         string
+	CertPEM            []byte
+	KeyPEM             []byte
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// ClientTLSConfig is like [DefaultClient], but with the TLS configuration described by the [TLSOptions].
+func ClientTLSConfig(opts TLSOptions) (*http.Client, error) {
+	cfg := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+
+	roots := opts.RootCAs
+	if opts.RootCAFile != "" {
+		pem, err := os.ReadFile(opts.RootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading root CA file: %w", err)
+		}
+		roots = append(append([]byte(nil), roots...), pem...)
+	}
+	if len(roots) != 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(roots) {
+			return nil, errors.New("parsing root CAs: no certificates found")
+		}
+		cfg.RootCAs = pool
+	}
+
+	var (
+		cert tls.Certificate
+		err  error
+	)
+	switch {
+	case opts.CertFile != "" || opts.KeyFile != "":
+		cert, err = tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+	case len(opts.CertPEM) != 0 || len(opts.KeyPEM) != 0:
+		cert, err = tls.X509KeyPair(opts.CertPEM, opts.KeyPEM)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading client certificate: %w", err)
+	}
+	if cert.Certificate != nil {
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	client := DefaultClient(opts.Timeout)
+	client.Transport.(*http.Transport).TLSClientConfig = cfg
+	return client, nil
+}
+
+// ClientUnixSocket is like [DefaultClient] with its default timeout, but connects to the unix domain socket at
+// socketPath for every request, e.g. for talking to local daemons such as Docker. Since the address in the URL is not
+// used for dialing, its host is merely a placeholder; by convention it is "unix", as in
+// http://unix/v1.41/containers/json. The Timeout of the returned client can be changed, or set to zero for long-lived
+// streams.
+func ClientUnixSocket(socketPath string) *http.Client {
+	client := DefaultClient(0)
+	t := client.Transport.(*http.Transport)
+	t.Proxy = nil
+	dialer := &net.Dialer{Timeout: defaultDialTimeout}
+	t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
+	return client
+}
+
+// PoolOptions configures the connection pool of the client created by [ClientPool]. Zero values are replaced by the
+// defaults: 100 for MaxIdleConns, 32 for MaxIdleConnsPerHost and 90 seconds for IdleConnTimeout. MaxConnsPerHost is
+// unlimited when zero. ExpectContinueTimeout is how long requests sent with [WithExpectContinue] wait for the server's
+// interim response before sending the body anyway; it defaults to a second. Timeout is passed to [DefaultClient].
+type PoolOptions struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	IdleConnTimeout       time.Duration
+	ExpectContinueTimeout time.Duration
+	Timeout               time.Duration
+}
+
+// ClientPool is like [DefaultClient], but with the connection pool tuned by the [PoolOptions]. High-throughput
+// services usually need MaxIdleConnsPerHost to be well above 2, the default of [net/http], so that connections to the
+// same host are reused instead of constantly being opened and closed.
+func ClientPool(opts PoolOptions) *http.Client {
+	if opts.MaxIdleConns == 0 {
+		opts.MaxIdleConns = 100
+	}
+	if opts.MaxIdleConnsPerHost == 0 {
+		opts.MaxIdleConnsPerHost = 32
+	}
+	if opts.IdleConnTimeout == 0 {
+		opts.IdleConnTimeout = 90 * time.Second
+	}
+	if opts.ExpectContinueTimeout == 0 {
+		opts.ExpectContinueTimeout = defaultExpectContinueTimeout
+	}
+
+	client := DefaultClient(opts.Timeout)
+	t := client.Transport.(*http.Transport)
+	t.MaxIdleConns = opts.MaxIdleConns
+	t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = opts.MaxConnsPerHost
+	t.IdleConnTimeout = opts.IdleConnTimeout
+	t.ExpectContinueTimeout = opts.ExpectContinueTimeout
+	return client
+}
+
+// newTransport returns a clone of [http.DefaultTransport] with the default dial, TLS handshake and expect-continue
+// timeouts.
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	t.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	t.ExpectContinueTimeout = defaultExpectContinueTimeout
+	return t
+}
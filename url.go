@@ -0,0 +1,202 @@
+package request
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildURL substitutes the {name} placeholders of base with the URL-escaped values of pathParams, and appends query
+// as URL-encoded query parameters. It returns an error if a placeholder has no value in pathParams.
+//
+// For example, BuildURL("https://api.example.com/users/{id}", map[string]string{"id": "a/b"}, nil) returns
+// https://api.example.com/users/a%2Fb.
+func BuildURL(base string, pathParams map[string]string, query map[string]string) (string, error) {
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(base, '{')
+		if start < 0 {
+			sb.WriteString(base)
+			break
+		}
+		end := strings.IndexByte(base[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed placeholder in %q", base)
+		}
+		end += start
+
+		name := base[start+1 : end]
+		value, ok := pathParams[name]
+		if !ok {
+			return "", fmt.Errorf("missing value for path parameter %q", name)
+		}
+		sb.WriteString(base[:start])
+		sb.WriteString(url.PathEscape(value))
+		base = base[end+1:]
+	}
+
+	u := sb.String()
+	if len(query) != 0 {
+		q := make(url.Values, len(query))
+		for k, v := range query {
+			q.Set(k, v)
+		}
+		sep := "?"
+		switch {
+		case strings.HasSuffix(u, "?") || strings.HasSuffix(u, "&"):
+			sep = ""
+		case strings.Contains(u, "?"):
+			sep = "&"
+		}
+		u += sep + q.Encode()
+	}
+	return u, nil
+}
+
+// QueryFromStruct encodes the exported fields of the struct v, or a pointer to it, as query parameters suitable for
+// [Request.ParamsMulti]. Keys are taken from the url struct tag, defaulting to the field name; a tag of "-" skips the
+// field, and the omitempty option skips it if it holds its zero value. Fields of embedded structs are promoted, unless
+// they have a tag of their own.
+//
+// Supported field types are strings, booleans, numbers, [time.Time] encoded as RFC 3339, [encoding.TextMarshaler]
+// values, and pointers to them; nil pointers are skipped. Slices and arrays of them produce a repeated key for each
+// element, e.g. ?id=1&id=2.
+func QueryFromStruct(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("encoding query: expected a struct, got %T", v)
+	}
+
+	q := make(url.Values)
+	err := encodeQueryStruct(q, rv)
+	if err != nil {
+		return nil, fmt.Errorf("encoding query: %w", err)
+	}
+	return q, nil
+}
+
+func encodeQueryStruct(q url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		fv := rv.Field(i)
+		tag, hasTag := f.Tag.Lookup("url")
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && !hasTag {
+			embedded := fv
+			if embedded.Kind() == reflect.Pointer && !embedded.IsNil() {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !isQueryValue(embedded) {
+				err := encodeQueryStruct(q, embedded)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		if fv.IsZero() && slices.Contains(strings.Split(opts, ","), "omitempty") {
+			continue
+		}
+
+		values := []reflect.Value{fv}
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && !isQueryValue(fv) {
+			values = values[:0]
+			for j := range fv.Len() {
+				values = append(values, fv.Index(j))
+			}
+		}
+		for _, v := range values {
+			s, ok, err := formatQueryValue(v)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if ok {
+				q.Add(name, s)
+			}
+		}
+	}
+	return nil
+}
+
+// isQueryValue reports whether v is encoded as a single value even though it is a struct, slice or array, i.e. it is a
+// [time.Time] or an [encoding.TextMarshaler].
+func isQueryValue(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	if _, ok := v.Interface().(time.Time); ok {
+		return true
+	}
+	_, ok := textMarshaler(v)
+	return ok
+}
+
+// textMarshaler returns v as an [encoding.TextMarshaler], if either it or its address implements the interface.
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return m, true
+	}
+	if v.CanAddr() {
+		m, ok := v.Addr().Interface().(encoding.TextMarshaler)
+		return m, ok
+	}
+	return nil, false
+}
+
+// formatQueryValue formats a single query value. ok is false if v is a nil pointer or interface.
+func formatQueryValue(v reflect.Value) (_ string, ok bool, err error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		v = v.Elem()
+	}
+
+	if t, isTime := v.Interface().(time.Time); isTime {
+		return t.Format(time.RFC3339), true, nil
+	}
+	if m, isMarshaler := textMarshaler(v); isMarshaler {
+		b, err := m.MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return string(b), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), true, nil
+	}
+	return "", false, fmt.Errorf("unsupported type %s", v.Type())
+}
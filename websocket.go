@@ -0,0 +1,162 @@
+package request
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+// websocketGUID is appended to the key of a WebSocket handshake for computing the accept value, as specified by
+// RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// UpgradeWebSocket performs the opening handshake of a WebSocket connection, as described by RFC 6455, and returns the
+// upgraded connection along with the response's metadata. Framing is left to the caller, e.g. by handing the
+// connection to a WebSocket library; closing it closes the underlying connection.
+//
+// [http.Client] doesn't hand out the hijacked connection itself, but a body reading and writing through it, which may
+// hold data already buffered by the transport. The returned [net.Conn] therefore reads, writes and closes through that
+// body, while its deadlines and addresses are those of the underlying connection, captured using [httptrace]. If no
+// connection was reported, e.g. with a custom [http.RoundTripper], setting deadlines fails and the addresses are nil.
+//
+// The request is sent with the GET method unless specified otherwise, and the ws and wss URL schemes are accepted as
+// http and https. The Upgrade, Connection, Sec-WebSocket-Key and Sec-WebSocket-Version headers are set, overriding
+// those of the request; others, such as Sec-WebSocket-Protocol or Origin, can be provided as usual. If the server
+// doesn't switch protocols, a [*StatusError] is returned, and a response with invalid Upgrade, Connection or
+// Sec-WebSocket-Accept headers is rejected.
+//
+// The connection lives within ctx, and is closed once it is done. Clients with a Timeout can't be used, since the
+// timeout would apply to the whole lifetime of the connection; [http.Client] makes the connection read-only in that
+// case, and an error is returned.
+func UpgradeWebSocket(
+	ctx context.Context, client *http.Client, r Request, opts ...Option,
+) (net.Conn, *ResponseMeta, error) {
+	switch {
+	case strings.HasPrefix(r.URL, "ws://"):
+		r.URL = "http://" + strings.TrimPrefix(r.URL, "ws://")
+	case strings.HasPrefix(r.URL, "wss://"):
+		r.URL = "https://" + strings.TrimPrefix(r.URL, "wss://")
+	}
+
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	key := base64.StdEncoding.EncodeToString(b[:])
+
+	r.Header = r.Header.Clone()
+	r.SetHeader("Upgrade", "websocket")
+	r.SetHeader("Connection", "Upgrade")
+	r.SetHeader("Sec-WebSocket-Key", key)
+	r.SetHeader("Sec-WebSocket-Version", "13")
+
+	var underlying net.Conn
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { underlying = info.Conn },
+	})
+	resp, err := SendRaw(ctx, client, r, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &ResponseMeta{
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		StatusCode: resp.StatusCode,
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, meta, &StatusError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
+	}
+
+	// The handshake is validated first, since the transport only makes the body writable for proper upgrades.
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		resp.Body.Close()
+		return nil, meta, fmt.Errorf("upgrading connection: unexpected Upgrade header %q", resp.Header.Get("Upgrade"))
+	}
+	if !hasToken(resp.Header.Values("Connection"), "upgrade") {
+		resp.Body.Close()
+		return nil, meta, fmt.Errorf("upgrading connection: unexpected Connection header %q", resp.Header.Get("Connection"))
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		resp.Body.Close()
+		return nil, meta, errors.New("upgrading connection: invalid Sec-WebSocket-Accept header")
+	}
+
+	body, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, meta, errors.New("upgrading connection: body is not writable, the client must not have a Timeout")
+	}
+	conn, ok := body.(net.Conn)
+	if !ok {
+		conn = &upgradedConn{ReadWriteCloser: body, conn: underlying}
+	}
+	return conn, meta, nil
+}
+
+// hasToken reports whether the comma-separated header values include token, case-insensitively.
+func hasToken(values []string, token string) bool {
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgradedConn is the [net.Conn] returned by [UpgradeWebSocket]. Reads, writes and closing go through the response
+// body, while the rest is delegated to the underlying connection, which may be nil.
+type upgradedConn struct {
+	io.ReadWriteCloser
+	conn net.Conn
+}
+
+func (c *upgradedConn) LocalAddr() net.Addr {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.LocalAddr()
+}
+
+func (c *upgradedConn) RemoteAddr() net.Addr {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.RemoteAddr()
+}
+
+func (c *upgradedConn) SetDeadline(t time.Time) error {
+	if c.conn == nil {
+		return errUnknownConn
+	}
+	return c.conn.SetDeadline(t)
+}
+
+func (c *upgradedConn) SetReadDeadline(t time.Time) error {
+	if c.conn == nil {
+		return errUnknownConn
+	}
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *upgradedConn) SetWriteDeadline(t time.Time) error {
+	if c.conn == nil {
+		return errUnknownConn
+	}
+	return c.conn.SetWriteDeadline(t)
+}
+
+// errUnknownConn is returned by upgradedConn when the underlying connection is unknown.
+var errUnknownConn = errors.New("setting deadline: underlying connection is unknown")